@@ -0,0 +1,163 @@
+//go:build capi
+
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+// C API of the Bengali phonetic engine, built with
+//
+//   go build -tags capi -buildmode=c-shared -o libbengalikeyboard.so .
+//
+// and tested from a C client in the capitest directory.
+//
+// Memory ownership: an engine returned by bk_engine_new must be released
+// with bk_engine_free. Every char* returned by the library is a NUL
+// terminated UTF-8 string owned by the caller and must be released with
+// bk_free. Strings passed in are only read during the call.
+//
+// An engine is safe for use from several threads.
+
+typedef uintptr_t bk_engine;
+*/
+import "C"
+
+import (
+	"runtime/cgo"
+	"sync"
+	"unsafe"
+
+	"bengali-keyboard/phonetic"
+)
+
+// cEngine is the state behind a bk_engine handle.
+type cEngine struct {
+	engine  *phonetic.Engine
+	session *phonetic.Session
+	mutex   sync.Mutex
+}
+
+func engineFromHandle(handle C.bk_engine) *cEngine {
+	return cgo.Handle(handle).Value().(*cEngine)
+}
+
+// current returns the engine and session in use, which a keymap switch
+// replaces together.
+func (ce *cEngine) current() (*phonetic.Engine, *phonetic.Session) {
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+	return ce.engine, ce.session
+}
+
+// bk_engine_new creates an engine using the default keymap.
+//
+//export bk_engine_new
+func bk_engine_new() C.bk_engine {
+	engine := phonetic.New()
+	return C.bk_engine(cgo.NewHandle(&cEngine{
+		engine:  engine,
+		session: engine.NewSession(),
+	}))
+}
+
+// bk_engine_free releases an engine. The handle must not be used afterwards.
+//
+//export bk_engine_free
+func bk_engine_free(handle C.bk_engine) {
+	cgo.Handle(handle).Delete()
+}
+
+// setKeyMap replaces the engine and session with ones using keymap.
+func (ce *cEngine) setKeyMap(keymap *phonetic.KeyMap) {
+	engine := phonetic.New(phonetic.WithKeyMap(keymap))
+	ce.mutex.Lock()
+	defer ce.mutex.Unlock()
+	ce.engine = engine
+	ce.session = engine.NewSession()
+}
+
+// bk_engine_load_keymap switches the engine to a named keymap and clears its
+// typing buffer. It returns 0 on success and -1 for an unknown name.
+//
+//export bk_engine_load_keymap
+func bk_engine_load_keymap(handle C.bk_engine, name *C.char) C.int {
+	keymap, ok := phonetic.LookupKeyMap(C.GoString(name))
+	if !ok {
+		return -1
+	}
+	engineFromHandle(handle).setKeyMap(keymap)
+	return 0
+}
+
+// bk_engine_load_keymap_file switches the engine to the keymap in a JSON
+// keymap file, the format of the keymap_file setting, and clears its
+// typing buffer. It returns 0 on success and -1 when the file cannot be
+// read or is not a valid keymap; the engine is then left unchanged.
+//
+//export bk_engine_load_keymap_file
+func bk_engine_load_keymap_file(handle C.bk_engine, path *C.char) C.int {
+	keymap, err := phonetic.LoadKeyMapFile(C.GoString(path))
+	if err != nil {
+		return -1
+	}
+	engineFromHandle(handle).setKeyMap(keymap)
+	return 0
+}
+
+// bk_engine_load_keymap_json is bk_engine_load_keymap_file for a keymap
+// passed as a JSON string.
+//
+//export bk_engine_load_keymap_json
+func bk_engine_load_keymap_json(handle C.bk_engine, json *C.char) C.int {
+	keymap, err := phonetic.ParseKeyMap([]byte(C.GoString(json)))
+	if err != nil {
+		return -1
+	}
+	engineFromHandle(handle).setKeyMap(keymap)
+	return 0
+}
+
+// bk_convert converts a whole Latin string to Bengali.
+//
+//export bk_convert
+func bk_convert(handle C.bk_engine, input *C.char) *C.char {
+	engine, _ := engineFromHandle(handle).current()
+	return C.CString(engine.Convert(C.GoString(input)))
+}
+
+// bk_process_key feeds one typed code point ('\b' for backspace) through the
+// engine's buffer. It stores the edit to apply in *backspaces and *text
+// (NULL when nothing is to be typed) and returns 1 when the original key
+// must be suppressed, 0 otherwise.
+//
+//export bk_process_key
+func bk_process_key(handle C.bk_engine, codepoint C.uint32_t, backspaces *C.int, text **C.char) C.int {
+	_, session := engineFromHandle(handle).current()
+	edit, suppress := session.Process(rune(codepoint))
+
+	*backspaces = C.int(edit.Backspaces)
+	*text = nil
+	if edit.Text != "" {
+		*text = C.CString(edit.Text)
+	}
+	if suppress {
+		return 1
+	}
+	return 0
+}
+
+// bk_engine_reset clears the engine's typing buffer, e.g. on focus change.
+//
+//export bk_engine_reset
+func bk_engine_reset(handle C.bk_engine) {
+	_, session := engineFromHandle(handle).current()
+	session.Reset()
+}
+
+// bk_free releases a string returned by the library.
+//
+//export bk_free
+func bk_free(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
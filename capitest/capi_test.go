@@ -0,0 +1,218 @@
+// Package capitest tests the C API from a C client. The client in
+// testdata/harness.c links the shared library built with the capi tag, so
+// neither it nor these tests end up in the library. The tests are skipped
+// without cgo or a C compiler.
+package capitest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"bengali-keyboard/phonetic"
+)
+
+// harnessPath is the built client, or empty with skipReason set.
+var harnessPath, skipReason string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "capitest")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	harnessPath, skipReason, err = buildHarness(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// buildHarness builds the library and the client linking it in dir. It
+// returns why the tests are skipped when cgo cannot be used here.
+func buildHarness(dir string) (path, skip string, err error) {
+	if runtime.GOOS == "windows" {
+		return "", "the harness links the library the Unix way", nil
+	}
+	out, err := exec.Command("go", "env", "CGO_ENABLED", "CC").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("go env: %w", err)
+	}
+	env := strings.Fields(string(out))
+	if len(env) < 2 || env[0] != "1" {
+		return "", "cgo is disabled", nil
+	}
+	cc, err := exec.LookPath(env[1])
+	if err != nil {
+		return "", fmt.Sprintf("no C compiler: %v", err), nil
+	}
+
+	build := exec.Command("go", "build", "-tags", "capi", "-buildmode=c-shared",
+		"-o", filepath.Join(dir, "libbengalikeyboard.so"), "bengali-keyboard")
+	if out, err := build.CombinedOutput(); err != nil {
+		return "", "", fmt.Errorf("building the library: %v\n%s", err, out)
+	}
+	path = filepath.Join(dir, "harness")
+	compile := exec.Command(cc, "-o", path, filepath.Join("testdata", "harness.c"),
+		"-I", dir, "-L", dir, "-lbengalikeyboard", "-Wl,-rpath,"+dir)
+	if out, err := compile.CombinedOutput(); err != nil {
+		return "", "", fmt.Errorf("compiling the harness: %v\n%s", err, out)
+	}
+	return path, "", nil
+}
+
+// harness is a running client with its own engine.
+type harness struct {
+	stdin  io.WriteCloser
+	stdout *bufio.Scanner
+}
+
+func startHarness(t *testing.T) *harness {
+	t.Helper()
+	if harnessPath == "" {
+		t.Skip(skipReason)
+	}
+	cmd := exec.Command(harnessPath)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		stdin.Close()
+		if err := cmd.Wait(); err != nil {
+			t.Errorf("harness: %v", err)
+		}
+	})
+	return &harness{stdin: stdin, stdout: bufio.NewScanner(stdout)}
+}
+
+// do runs a harness command and returns its result line.
+func (h *harness) do(t *testing.T, command string) string {
+	t.Helper()
+	if _, err := fmt.Fprintln(h.stdin, command); err != nil {
+		t.Fatal(err)
+	}
+	if !h.stdout.Scan() {
+		t.Fatalf("%s: no result from the harness: %v", command, h.stdout.Err())
+	}
+	result := h.stdout.Text()
+	if strings.HasPrefix(result, "error: ") {
+		t.Fatalf("%s: %s", command, result)
+	}
+	return result
+}
+
+// processKey runs bk_process_key for ch.
+func (h *harness) processKey(t *testing.T, ch rune) (phonetic.Edit, bool) {
+	t.Helper()
+	result := h.do(t, "key "+strconv.Itoa(int(ch)))
+	fields := strings.SplitN(result, " ", 3)
+	if len(fields) != 3 {
+		t.Fatalf("key %q: bad result %q", ch, result)
+	}
+	backspaces, err := strconv.Atoi(fields[1])
+	if err != nil {
+		t.Fatalf("key %q: bad result %q", ch, result)
+	}
+	return phonetic.Edit{Backspaces: backspaces, Text: fields[2]}, fields[0] == "1"
+}
+
+func TestConvert(t *testing.T) {
+	h := startHarness(t)
+	if got, want := h.do(t, "convert ami banglay gan gai"), "আমি বাংলায় গান গাই"; got != want {
+		t.Errorf("bk_convert = %q, want %q", got, want)
+	}
+}
+
+func TestProcessKey(t *testing.T) {
+	h := startHarness(t)
+
+	var edits []phonetic.Edit
+	for _, ch := range "ami " {
+		edit, suppress := h.processKey(t, ch)
+		if wantSuppress := ch == ' '; suppress != wantSuppress {
+			t.Errorf("key %q: suppress = %v, want %v", ch, suppress, wantSuppress)
+		}
+		if !edit.IsEmpty() {
+			edits = append(edits, edit)
+		}
+	}
+	want := []phonetic.Edit{{Backspaces: 3, Text: "আমি "}}
+	if len(edits) != len(want) || edits[0] != want[0] {
+		t.Errorf("edits = %+v, want %+v", edits, want)
+	}
+
+	// A reset drops the buffer, so the next space commits nothing
+	h.processKey(t, 'k')
+	h.do(t, "reset")
+	if edit, suppress := h.processKey(t, ' '); !edit.IsEmpty() || suppress {
+		t.Errorf("space after reset = %+v, suppress %v, want no edit", edit, suppress)
+	}
+}
+
+func TestLoadKeyMap(t *testing.T) {
+	h := startHarness(t)
+
+	if ret := h.do(t, "keymap no-such-keymap"); ret != "-1" {
+		t.Errorf("unknown keymap returned %s, want -1", ret)
+	}
+	if ret := h.do(t, "keymap "+phonetic.AvroKeyMapName); ret != "0" {
+		t.Fatalf("loading %s returned %s, want 0", phonetic.AvroKeyMapName, ret)
+	}
+	if got := h.do(t, "convert sh"); got != "শ" {
+		t.Errorf("bk_convert(sh) with the Avro keymap = %q, want শ", got)
+	}
+}
+
+func TestLoadKeyMapFile(t *testing.T) {
+	h := startHarness(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keymap.json")
+	if err := os.WriteFile(path, []byte(`{"base": "phonetic", "patterns": {"x": "ক"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"patterns": `), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		command string
+		ret     string
+		want    string // conversion of "xa" afterwards
+	}{
+		{"keymap_file " + filepath.Join(dir, "nope.json"), "-1", "xআ"},
+		{"keymap_file " + bad, "-1", "xআ"},
+		{"keymap_file " + path, "0", "কা"},
+		{`keymap_json {"base": "nope"}`, "-1", "কা"},
+		{`keymap_json {"base": "phonetic"}`, "0", "xআ"},
+	}
+	for _, tt := range tests {
+		if ret := h.do(t, tt.command); ret != tt.ret {
+			t.Errorf("%s: returned %s, want %s", tt.command, ret, tt.ret)
+		}
+		if got := h.do(t, "convert xa"); got != tt.want {
+			t.Errorf("%s: bk_convert(xa) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
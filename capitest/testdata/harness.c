@@ -0,0 +1,69 @@
+// Test client of the C API for capi_test.go. It links libbengalikeyboard
+// the way another input framework would and runs one command per line of
+// standard input, writing one line of result for each:
+//
+//   convert <text>         the conversion
+//   key <code point>       <suppress> <backspaces> <text>
+//   keymap <name>          the return value of bk_engine_load_keymap
+//   keymap_file <path>     likewise for bk_engine_load_keymap_file
+//   keymap_json <json>     likewise for bk_engine_load_keymap_json
+//   reset                  ok
+//
+// Every returned string is copied out and released with bk_free.
+
+#include <stdint.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+#include "libbengalikeyboard.h"
+
+static void print_owned(const char *prefix, char *s) {
+	printf("%s%s\n", prefix, s == NULL ? "" : s);
+	bk_free(s);
+}
+
+int main(void) {
+	bk_engine engine = bk_engine_new();
+	char line[4096];
+
+	while (fgets(line, sizeof line, stdin) != NULL) {
+		line[strcspn(line, "\n")] = 0;
+		char *arg = strchr(line, ' ');
+		if (arg != NULL) {
+			*arg++ = 0;
+		} else {
+			arg = "";
+		}
+
+		if (strcmp(line, "convert") == 0) {
+			print_owned("", bk_convert(engine, arg));
+		} else if (strcmp(line, "key") == 0) {
+			int backspaces = -1;
+			char *text = (char *)1; // must be overwritten with a string or NULL
+			int suppress = bk_process_key(engine, (uint32_t)strtoul(arg, NULL, 10), &backspaces, &text);
+			if (text == (char *)1) {
+				printf("error: text not set\n");
+			} else {
+				char prefix[32];
+				snprintf(prefix, sizeof prefix, "%d %d ", suppress, backspaces);
+				print_owned(prefix, text);
+			}
+		} else if (strcmp(line, "keymap") == 0) {
+			printf("%d\n", bk_engine_load_keymap(engine, arg));
+		} else if (strcmp(line, "keymap_file") == 0) {
+			printf("%d\n", bk_engine_load_keymap_file(engine, arg));
+		} else if (strcmp(line, "keymap_json") == 0) {
+			printf("%d\n", bk_engine_load_keymap_json(engine, arg));
+		} else if (strcmp(line, "reset") == 0) {
+			bk_engine_reset(engine);
+			printf("ok\n");
+		} else {
+			printf("error: unknown command %s\n", line);
+		}
+		fflush(stdout);
+	}
+
+	bk_engine_free(engine);
+	return 0;
+}
@@ -0,0 +1,37 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// Subcommands available as the first command line argument. Without one
+// the tray keyboard starts.
+var commands = map[string]func(args []string) error{
+	"complete": runComplete,
+	"convert":  runConvert,
+	"macro":    runMacro,
+	"repl":     runREPL,
+	"serve":    runServe,
+	"stats":    runStats,
+	"train":    runTrain,
+}
+
+func runCommand(name string, args []string) int {
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+		return 2
+	}
+	if err := readSettings(); err != nil {
+		slog.Error("loading settings", "err", err)
+	}
+	if err := cmd(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+//go:build !js
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"bengali-keyboard/phonetic"
+)
+
+// runComplete prints the completions of partly typed Latin words, or with
+// -bench measures how long completing takes after each keystroke.
+func runComplete(args []string) error {
+	flags := flag.NewFlagSet("complete", flag.ContinueOnError)
+	limit := flags.Int("n", 5, "completions per word")
+	bench := flags.Bool("bench", false, "time the completions of every prefix of the words")
+	rounds := flags.Int("rounds", 1000, "times each prefix is completed with -bench")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	index := completionIndex()
+	if index == nil {
+		return errors.New(`completion needs "completion": true and a "prediction_model" in the settings`)
+	}
+	if flags.NArg() == 0 {
+		return errors.New("usage: complete [-n N] [-bench [-rounds N]] WORD...")
+	}
+
+	if !*bench {
+		for _, word := range flags.Args() {
+			completions := index.Complete(phonetic.LatinKey(word), *limit)
+			fmt.Printf("%s: %s\n", word, strings.Join(completions, " "))
+		}
+		return nil
+	}
+
+	var keystrokes int
+	var total, slowest time.Duration
+	for _, word := range flags.Args() {
+		for end := range word {
+			prefix := word[:end+1]
+			start := time.Now()
+			for i := 0; i < *rounds; i++ {
+				index.Complete(phonetic.LatinKey(prefix), *limit)
+			}
+			elapsed := time.Since(start) / time.Duration(max(*rounds, 1))
+			total += elapsed
+			slowest = max(slowest, elapsed)
+			keystrokes++
+		}
+	}
+	fmt.Printf("%d words indexed, %d keystrokes: %v mean, %v slowest per keystroke\n",
+		index.Len(), keystrokes, total/time.Duration(max(keystrokes, 1)), slowest)
+	return nil
+}
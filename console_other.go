@@ -0,0 +1,10 @@
+//go:build !windows && !linux && !js
+
+package main
+
+import "errors"
+
+// makeRaw is not implemented here; the REPL then reads line by line.
+func makeRaw() (func(), error) {
+	return nil, errors.New("raw terminal mode not supported on this platform")
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	ENABLE_PROCESSED_INPUT = 0x0001
+	ENABLE_LINE_INPUT      = 0x0002
+	ENABLE_ECHO_INPUT      = 0x0004
+)
+
+var (
+	getConsoleMode = kernel32.NewProc("GetConsoleMode")
+	setConsoleMode = kernel32.NewProc("SetConsoleMode")
+)
+
+// makeRaw switches the console behind stdin to unbuffered, unechoed input
+// and returns a function restoring the previous mode.
+func makeRaw() (func(), error) {
+	handle := uintptr(syscall.Handle(os.Stdin.Fd()))
+
+	var mode uint32
+	if ret, _, err := getConsoleMode.Call(handle, uintptr(unsafe.Pointer(&mode))); ret == 0 {
+		return nil, fmt.Errorf("GetConsoleMode: %w", err)
+	}
+
+	raw := mode &^ (ENABLE_PROCESSED_INPUT | ENABLE_LINE_INPUT | ENABLE_ECHO_INPUT)
+	if ret, _, err := setConsoleMode.Call(handle, uintptr(raw)); ret == 0 {
+		return nil, fmt.Errorf("SetConsoleMode: %w", err)
+	}
+
+	return func() {
+		setConsoleMode.Call(handle, uintptr(mode))
+	}, nil
+}
@@ -0,0 +1,27 @@
+//go:build !js
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// runConvert prints the Bengali conversion of its arguments, or of each
+// line of standard input when there are none.
+func runConvert(args []string) error {
+	setupEngines()
+
+	if len(args) > 0 {
+		fmt.Println(keyboardEngine.Convert(strings.Join(args, " ")))
+		return nil
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		fmt.Println(keyboardEngine.Convert(scanner.Text()))
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,23 @@
+package main
+
+import "bengali-keyboard/phonetic"
+
+// keyboardEngine converts with the default scheme, once it is set up from
+// the settings.
+var keyboardEngine *phonetic.Engine
+
+// Values of the normalization and digit_mode settings, also used by
+// the browser build's configure.
+var normForms = map[string]phonetic.NormForm{
+	"":     phonetic.NFC,
+	"nfc":  phonetic.NFC,
+	"nfd":  phonetic.NFD,
+	"none": phonetic.NormNone,
+}
+
+var digitModes = map[string]phonetic.DigitMode{
+	"":        phonetic.DigitsBengali,
+	"bengali": phonetic.DigitsBengali,
+	"ascii":   phonetic.DigitsASCII,
+	"context": phonetic.DigitsContext,
+}
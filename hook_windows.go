@@ -0,0 +1,98 @@
+package main
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	WM_REINSTALL_HOOK = WM_USER + 2
+
+	hookCheckInterval = 5 * time.Second
+	// Key presses this much newer than the hook's last event mean it missed them
+	hookMissLimit = 1000 // milliseconds
+)
+
+// keyboardHook is the low-level keyboard hook. Windows removes such hooks
+// without notice when they take longer than LowLevelHooksTimeout, so a
+// watchdog compares the last event the hook saw with the last key press
+// raw input reported, and has the hook reinstalled when key presses went
+// past it. Raw input only covers the keyboard, so mouse input never makes
+// it reinstall a working hook.
+type keyboardHook struct {
+	handle    uintptr // only used on the message loop thread
+	callback  uintptr
+	hInstance syscall.Handle
+	lastEvent atomic.Uint32 // tick count of the last event the hook got
+	lastInput atomic.Uint32 // tick count of the last raw keyboard input
+}
+
+var kbdHook = &keyboardHook{}
+
+// install sets the hook. It must be called on the thread running the
+// message loop, which is where Windows calls it.
+func (h *keyboardHook) install(hInstance syscall.Handle) error {
+	if h.callback == 0 {
+		h.callback = syscall.NewCallback(keyboardHookProc)
+	}
+	h.hInstance = hInstance
+
+	handle, err := setHook(WH_KEYBOARD_LL, h.callback, hInstance)
+	if err != nil {
+		return err
+	}
+	h.handle = handle
+	h.lastEvent.Store(h.lastInput.Load())
+	return nil
+}
+
+func (h *keyboardHook) uninstall() error {
+	if h.handle == 0 {
+		return nil
+	}
+	err := unhook(h.handle)
+	h.handle = 0
+	return err
+}
+
+// reinstall replaces the hook. Unhooking one Windows already removed
+// fails, which is expected here.
+func (h *keyboardHook) reinstall() {
+	if err := h.uninstall(); err != nil {
+		slog.Debug("removing stale keyboard hook", "err", err)
+	}
+	if err := h.install(h.hInstance); err != nil {
+		slog.Error("reinstalling keyboard hook", "err", err)
+		return
+	}
+	slog.Info("keyboard hook reinstalled")
+}
+
+// seen records the time of an event the hook received.
+func (h *keyboardHook) seen(tick uint32) {
+	h.lastEvent.Store(tick)
+}
+
+// input records the time of a key press raw input reported.
+func (h *keyboardHook) input(tick uint32) {
+	h.lastInput.Store(tick)
+}
+
+// watch checks the hook every hookCheckInterval and asks the window
+// thread to reinstall it when it has stopped receiving key presses.
+func (h *keyboardHook) watch(hwnd syscall.Handle) {
+	for range time.Tick(hookCheckInterval) {
+		tick := h.lastInput.Load()
+		// Tick counts wrap after 49 days; the difference stays right
+		if int32(tick-h.lastEvent.Load()) <= hookMissLimit {
+			continue
+		}
+
+		h.lastEvent.Store(tick)
+		if err := postMessage(hwnd, WM_REINSTALL_HOOK, 0, 0); err != nil {
+			slog.Error("requesting hook reinstall", "err", err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"bengali-keyboard/phonetic"
+)
+
+const (
+	LLKHF_INJECTED = 0x00000010
+
+	// injectedTag marks the events the keyboard sends in DwExtraInfo, so the
+	// hook can tell them from keys typed by the user or other tools.
+	injectedTag = 0x424B4244 // "BKBD"
+)
+
+// isOwnInput reports whether the hook is seeing an event the keyboard
+// injected itself.
+func isOwnInput(kbd *KBDLLHOOKSTRUCT) bool {
+	return kbd.Flags&LLKHF_INJECTED != 0 && kbd.DwExtraInfo == injectedTag
+}
+
+// editInputs translates an edit into the key events that perform it, in
+// the order they must be sent.
+func editInputs(edit phonetic.Edit) []INPUT {
+	inputs := make([]INPUT, 0, 2*(edit.Backspaces+utf8.RuneCountInString(edit.Text)))
+	for i := 0; i < edit.Backspaces; i++ {
+		inputs = append(inputs, keyInputs(VK_BACK)...)
+	}
+	for _, ch := range edit.Text {
+		inputs = append(inputs, charInputs(ch)...)
+	}
+	return inputs
+}
+
+// charInputs returns the key down and up events typing ch, one pair per
+// UTF-16 code unit with the high surrogate first. Whitespace goes through
+// its virtual key so applications see a real Enter or Tab.
+func charInputs(ch rune) []INPUT {
+	switch ch {
+	case ' ':
+		return keyInputs(VK_SPACE)
+	case '\n':
+		return keyInputs(VK_RETURN)
+	case '\t':
+		return keyInputs(VK_TAB)
+	}
+	var inputs []INPUT
+	for _, unit := range utf16Units(ch) {
+		inputs = append(inputs, keyPair(KEYBDINPUT{Wscan: unit, DwFlags: KEYEVENTF_UNICODE})...)
+	}
+	return inputs
+}
+
+// utf16Units encodes ch as the UTF-16 code units KEYEVENTF_UNICODE takes:
+// one for the BMP, a high and a low surrogate above it. Runes that cannot
+// be encoded become U+FFFD.
+func utf16Units(ch rune) []uint16 {
+	if high, low := utf16.EncodeRune(ch); high != unicode.ReplacementChar {
+		return []uint16{uint16(high), uint16(low)}
+	}
+	if ch < 0 || ch > 0xFFFF || utf16.IsSurrogate(ch) {
+		ch = unicode.ReplacementChar
+	}
+	return []uint16{uint16(ch)}
+}
+
+// keyInputs returns the key down and up events of a virtual key.
+func keyInputs(vk uint16) []INPUT {
+	return keyPair(KEYBDINPUT{Wvk: vk})
+}
+
+func keyPair(down KEYBDINPUT) []INPUT {
+	down.DwExtraInfo = injectedTag
+	up := down
+	up.DwFlags |= KEYEVENTF_KEYUP
+	return []INPUT{
+		{Type: INPUT_KEYBOARD, Ki: down},
+		{Type: INPUT_KEYBOARD, Ki: up},
+	}
+}
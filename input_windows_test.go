@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+
+	"bengali-keyboard/phonetic"
+)
+
+func TestEditInputs(t *testing.T) {
+	inputs := editInputs(phonetic.Edit{Backspaces: 2, Text: "আ \t\n"})
+
+	// Two backspaces and four characters, each a key down and up
+	if len(inputs) != 12 {
+		t.Fatalf("got %d inputs, want 12", len(inputs))
+	}
+	for i, in := range inputs {
+		if in.Type != INPUT_KEYBOARD {
+			t.Errorf("input %d: type %d, want INPUT_KEYBOARD", i, in.Type)
+		}
+		if in.Ki.DwExtraInfo != injectedTag {
+			t.Errorf("input %d: extra info %#x, want injectedTag", i, in.Ki.DwExtraInfo)
+		}
+		if up := in.Ki.DwFlags&KEYEVENTF_KEYUP != 0; up != (i%2 == 1) {
+			t.Errorf("input %d: key up %v, want %v", i, up, i%2 == 1)
+		}
+	}
+
+	wantVK := []uint16{VK_BACK, VK_BACK, 0, VK_SPACE, VK_TAB, VK_RETURN}
+	for i, vk := range wantVK {
+		if got := inputs[2*i].Ki.Wvk; got != vk {
+			t.Errorf("key %d: virtual key %#x, want %#x", i, got, vk)
+		}
+	}
+	if ki := inputs[4].Ki; ki.Wscan != 'আ' || ki.DwFlags&KEYEVENTF_UNICODE == 0 {
+		t.Errorf("আ sent as %+v, want a KEYEVENTF_UNICODE event", ki)
+	}
+}
+
+func TestIsOwnInput(t *testing.T) {
+	tests := []struct {
+		name string
+		kbd  KBDLLHOOKSTRUCT
+		want bool
+	}{
+		{"typed", KBDLLHOOKSTRUCT{}, false},
+		{"injected by another tool", KBDLLHOOKSTRUCT{Flags: LLKHF_INJECTED}, false},
+		{"tag without injected flag", KBDLLHOOKSTRUCT{DwExtraInfo: injectedTag}, false},
+		{"own", KBDLLHOOKSTRUCT{Flags: LLKHF_INJECTED, DwExtraInfo: injectedTag}, true},
+	}
+	for _, tt := range tests {
+		if got := isOwnInput(&tt.kbd); got != tt.want {
+			t.Errorf("%s: isOwnInput = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUTF16Units(t *testing.T) {
+	tests := []struct {
+		ch   rune
+		want []uint16
+	}{
+		{'a', []uint16{0x0061}},
+		{'ক', []uint16{0x0995}},
+		{'\U0001F600', []uint16{0xD83D, 0xDE00}},
+		{0xD800, []uint16{0xFFFD}},
+		{0xDFFF, []uint16{0xFFFD}},
+		{0x110000, []uint16{0xFFFD}},
+		{-1, []uint16{0xFFFD}},
+	}
+	for _, tt := range tests {
+		got := utf16Units(tt.ch)
+		if len(got) != len(tt.want) {
+			t.Errorf("utf16Units(%U) = %#x, want %#x", tt.ch, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("utf16Units(%U) = %#x, want %#x", tt.ch, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestCharInputsSurrogates(t *testing.T) {
+	inputs := charInputs('\U0001F600')
+
+	// High surrogate down and up, then the low one
+	want := []struct {
+		scan uint16
+		up   bool
+	}{{0xD83D, false}, {0xD83D, true}, {0xDE00, false}, {0xDE00, true}}
+	if len(inputs) != len(want) {
+		t.Fatalf("got %d inputs, want %d", len(inputs), len(want))
+	}
+	for i, w := range want {
+		ki := inputs[i].Ki
+		if ki.Wscan != w.scan || (ki.DwFlags&KEYEVENTF_KEYUP != 0) != w.up || ki.DwFlags&KEYEVENTF_UNICODE == 0 {
+			t.Errorf("input %d = %+v, want unit %#x key up %v", i, ki, w.scan, w.up)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	logFileName  = "bengali-keyboard.log"
+	logFileSize  = 1 << 20 // rotate after 1 MiB
+	logFilesKept = 3
+)
+
+var logLevels = map[string]slog.Level{
+	"":      slog.LevelInfo,
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// setupLogging sends log records to the console and to a rotating file in
+// the configuration directory. Without a usable file the console still
+// gets them.
+func setupLogging() io.Closer {
+	level := &slog.HandlerOptions{Level: logLevels[userSettings.LogLevel]}
+	var out io.Writer = os.Stderr
+
+	file, err := openLogFile()
+	if err == nil {
+		out = io.MultiWriter(os.Stderr, file)
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(out, level)))
+
+	if err != nil {
+		slog.Warn("log file unavailable", "err", err)
+		return io.NopCloser(nil)
+	}
+	return file
+}
+
+func openLogFile() (*rotatingFile, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return openRotatingFile(filepath.Join(dir, logFileName), logFileSize, logFilesKept)
+}
+
+// debugEnabled reports whether debug records are logged, so the key
+// pipeline can skip building them otherwise.
+func debugEnabled() bool {
+	return slog.Default().Enabled(context.Background(), slog.LevelDebug)
+}
+
+// content is typed text in a log record. Only its length is logged unless
+// log_content is set.
+type content string
+
+func (c content) LogValue() slog.Value {
+	if userSettings.LogContent {
+		return slog.StringValue(string(c))
+	}
+	return slog.StringValue(fmt.Sprintf("[%d chars]", utf8.RuneCountInString(string(c))))
+}
+
+// rotatingFile is a log file that is renamed to path.1 once it grows past
+// maxSize, keeping up to keep old files.
+type rotatingFile struct {
+	path    string
+	maxSize int64
+	keep    int
+
+	file  *os.File
+	size  int64
+	mutex sync.Mutex
+}
+
+func openRotatingFile(path string, maxSize int64, keep int) (*rotatingFile, error) {
+	f := &rotatingFile{path: path, maxSize: maxSize, keep: keep}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate shifts path.N to path.N+1, dropping the oldest, and starts a new
+// file at path.
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	os.Remove(fmt.Sprintf("%s.%d", f.path, f.keep))
+	for i := f.keep - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	if err := os.Rename(f.path, f.path+".1"); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+func (f *rotatingFile) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -0,0 +1,109 @@
+//go:build !js
+
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	f, err := openRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each line fills half the file, so every second one rotates
+	for _, line := range []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n", "eeee\n", "ffff\n", "gggg\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The oldest lines are pruned past the two kept files
+	for name, want := range map[string]string{
+		"test.log":   "gggg\n",
+		"test.log.1": "eeee\nffff\n",
+		"test.log.2": "cccc\ndddd\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(path), name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("a third old file was kept: %v", err)
+	}
+
+	if _, err := f.Write([]byte("x")); err == nil {
+		t.Error("Write after Close succeeded")
+	}
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("12345678\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The size of what is already there counts toward the limit
+	f, err := openRotatingFile(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte("next\n")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "next\n" {
+		t.Errorf("log after writing past the limit = %q, want a new file", data)
+	}
+	if data, _ := os.ReadFile(path + ".1"); string(data) != "12345678\n" {
+		t.Errorf("old log = %q, want the existing lines", data)
+	}
+
+	// A single record larger than the limit still goes to an empty file
+	big := strings.Repeat("z", 20) + "\n"
+	if _, err := f.Write([]byte(big)); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != big {
+		t.Errorf("log after an oversized record = %q, want only it", data)
+	}
+}
+
+func TestContentRedaction(t *testing.T) {
+	saved := userSettings
+	t.Cleanup(func() { userSettings = saved })
+
+	tests := []struct {
+		logContent bool
+		want       string
+	}{
+		{false, `char="[1 chars]" buffer="[3 chars]" text="[0 chars]"`},
+		{true, `char=k buffer=আমি text=""`},
+	}
+	for _, tt := range tests {
+		userSettings.LogContent = tt.logContent
+
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+		logger.Info("key", "char", content("k"), "buffer", content("আমি"), "text", content(""))
+		if got := buf.String(); !strings.Contains(got, tt.want) {
+			t.Errorf("log_content %v: logged %q, want it to contain %q", tt.logContent, got, tt.want)
+		}
+		if !tt.logContent && strings.Contains(buf.String(), "আমি") {
+			t.Errorf("log_content false: typed text logged in %q", buf.String())
+		}
+	}
+}
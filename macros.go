@@ -0,0 +1,196 @@
+//go:build !js
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"bengali-keyboard/phonetic"
+)
+
+const macroUsage = `usage: macro list
+       macro add NAME TEXT...
+       macro remove NAME
+       macro export [FILE]
+       macro import [-replace] FILE`
+
+func macrosPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "macros.json"), nil
+}
+
+// loadMacros reads macros.json from the configuration directory. Having
+// no file means having no macros.
+func loadMacros() (phonetic.Macros, error) {
+	path, err := macrosPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return phonetic.Macros{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	macros, err := parseMacros(data)
+	if err != nil {
+		return nil, fmt.Errorf("macros.json: %w", err)
+	}
+	return macros, nil
+}
+
+func parseMacros(data []byte) (phonetic.Macros, error) {
+	macros := phonetic.Macros{}
+	if err := json.Unmarshal(data, &macros); err != nil {
+		return nil, err
+	}
+	for name, text := range macros {
+		if err := phonetic.ValidateMacro(name, text); err != nil {
+			return nil, err
+		}
+	}
+	return macros, nil
+}
+
+// checkMacroNames reports the first macro, in name order, whose name
+// clashes with the keymap of a scheme.
+func checkMacroNames(macros phonetic.Macros, schemes []phonetic.Scheme) error {
+	names := make([]string, 0, len(macros))
+	for name := range macros {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, scheme := range schemes {
+			if err := phonetic.CheckMacroName(name, scheme.KeyMap); err != nil {
+				return fmt.Errorf("scheme %q: %w", scheme.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func saveMacros(macros phonetic.Macros) error {
+	path, err := macrosPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := writeMacros(&buf, macros); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// writeMacros writes macros as indented JSON with their text unescaped.
+func writeMacros(w io.Writer, macros phonetic.Macros) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(macros)
+}
+
+// runMacro lists, edits, imports and exports the macros in macros.json.
+func runMacro(args []string) error {
+	if len(args) == 0 {
+		return errors.New(macroUsage)
+	}
+
+	macros, err := loadMacros()
+	if err != nil {
+		return err
+	}
+
+	switch cmd, args := args[0], args[1:]; {
+	case cmd == "list" && len(args) == 0:
+		names := make([]string, 0, len(macros))
+		for name := range macros {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		now := time.Now()
+		for _, name := range names {
+			expansion, _ := macros.Expand(name, now)
+			fmt.Printf("%-12s %s\n", name, expansion)
+		}
+		return nil
+
+	case cmd == "add" && len(args) >= 2:
+		name, text := args[0], strings.Join(args[1:], " ")
+		if err := phonetic.ValidateMacro(name, text); err != nil {
+			return err
+		}
+		if err := checkMacroNames(phonetic.Macros{name: text}, configuredSchemes()); err != nil {
+			return err
+		}
+		macros[name] = text
+		return saveMacros(macros)
+
+	case cmd == "remove" && len(args) == 1:
+		if _, ok := macros[args[0]]; !ok {
+			return fmt.Errorf("no macro %q", args[0])
+		}
+		delete(macros, args[0])
+		return saveMacros(macros)
+
+	case cmd == "export" && len(args) <= 1:
+		if len(args) == 0 {
+			return writeMacros(os.Stdout, macros)
+		}
+		file, err := os.Create(args[0])
+		if err != nil {
+			return err
+		}
+		if err := writeMacros(file, macros); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+
+	case cmd == "import" && len(args) >= 1 && len(args) <= 2:
+		replace := args[0] == "-replace"
+		if replace {
+			args = args[1:]
+		}
+		if len(args) != 1 {
+			return errors.New(macroUsage)
+		}
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		imported, err := parseMacros(data)
+		if err == nil {
+			err = checkMacroNames(imported, configuredSchemes())
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		if replace {
+			macros = phonetic.Macros{}
+		}
+		for name, text := range imported {
+			macros[name] = text
+		}
+		fmt.Printf("Imported %d macros\n", len(imported))
+		return saveMacros(macros)
+	}
+	return errors.New(macroUsage)
+}
@@ -0,0 +1,176 @@
+//go:build !js
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"bengali-keyboard/phonetic"
+)
+
+// useTempConfigDir points the configuration directory at a new temporary
+// one for the rest of the test.
+func useTempConfigDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+}
+
+func TestParseMacros(t *testing.T) {
+	macros, err := parseMacros([]byte(`{"bdgov": "বাংলাদেশ সরকার", "aj": "আজ {date}"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (phonetic.Macros{"bdgov": "বাংলাদেশ সরকার", "aj": "আজ {date}"}); !reflect.DeepEqual(macros, want) {
+		t.Errorf("parseMacros = %v, want %v", macros, want)
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"invalid JSON", `{"bdgov": `, "unexpected end of JSON input"},
+		{"not an object", `["bdgov"]`, "cannot unmarshal array"},
+		{"not a string", `{"bdgov": 1}`, "cannot unmarshal number"},
+		{"name with a space", `{"bd gov": "বাংলাদেশ সরকার"}`, `macro name "bd gov" is not a single word`},
+		{"empty", `{"bdgov": ""}`, `macro "bdgov" is empty`},
+		{"unknown placeholder", `{"aj": "{today}"}`, "unknown placeholder {today}"},
+	}
+	for _, tt := range tests {
+		_, err := parseMacros([]byte(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v, want one containing %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestWriteMacros(t *testing.T) {
+	macros := phonetic.Macros{"heart": "<3 & ❤️", "bdgov": "বাংলাদেশ সরকার"}
+	var buf bytes.Buffer
+	if err := writeMacros(&buf, macros); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"bdgov\": \"বাংলাদেশ সরকার\",\n  \"heart\": \"<3 & ❤️\"\n}\n"
+	if buf.String() != want {
+		t.Errorf("writeMacros wrote %q, want %q", buf.String(), want)
+	}
+	if parsed, err := parseMacros(buf.Bytes()); err != nil || !reflect.DeepEqual(parsed, macros) {
+		t.Errorf("written macros read back as %v, %v", parsed, err)
+	}
+}
+
+func TestCheckMacroNames(t *testing.T) {
+	schemes := phonetic.BuiltinSchemes()
+	if err := checkMacroNames(phonetic.Macros{"bdgov": "x", "aj": "y"}, schemes); err != nil {
+		t.Errorf("checkMacroNames of macros without clashes: %v", err)
+	}
+
+	tests := []struct {
+		macros phonetic.Macros
+		want   string
+	}{
+		{phonetic.Macros{"bdgov": "x", "kh": "y"}, `scheme "phonetic": macro name "kh" is a keymap pattern`},
+		{phonetic.Macros{"<3": "x", "kh": "y"}, `scheme "phonetic": macro name "<3" is a keymap expansion`},
+		// x is no pattern of the phonetic scheme, only of avro
+		{phonetic.Macros{"x": "y"}, `scheme "avro": macro name "x" is a keymap pattern`},
+	}
+	for _, tt := range tests {
+		err := checkMacroNames(tt.macros, schemes)
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || err.Error() != tt.want) {
+			t.Errorf("checkMacroNames(%v) = %v, want %q", tt.macros, err, tt.want)
+		}
+	}
+}
+
+func TestMacroCommand(t *testing.T) {
+	useTempConfigDir(t)
+
+	saved := func() phonetic.Macros {
+		t.Helper()
+		macros, err := loadMacros()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return macros
+	}
+	run := func(args ...string) error {
+		t.Helper()
+		return runMacro(args)
+	}
+
+	if err := run("add", "bdgov", "বাংলাদেশ", "সরকার"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("add", "aj", "আজ {date}"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := saved(), (phonetic.Macros{"bdgov": "বাংলাদেশ সরকার", "aj": "আজ {date}"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("after add: %v, want %v", got, want)
+	}
+
+	for _, args := range [][]string{
+		{"add", "k", "ক"},
+		{"add", ":)", "হাসি"},
+		{"add", "aj", "{today}"},
+		{"add", "bdgov"},
+		{"remove", "nope"},
+		{"rename", "aj"},
+		{},
+	} {
+		if err := run(args...); err == nil {
+			t.Errorf("macro %q succeeded", args)
+		}
+	}
+
+	if err := run("remove", "aj"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := saved(), (phonetic.Macros{"bdgov": "বাংলাদেশ সরকার"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("after remove: %v, want %v", got, want)
+	}
+
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	if err := run("import", write("clash.json", `{"dhk": "ঢাকা", "kh": "খ"}`)); err == nil {
+		t.Error("importing a macro named like a pattern succeeded")
+	}
+	if err := run("import", write("more.json", `{"dhk": "ঢাকা"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := saved(), (phonetic.Macros{"bdgov": "বাংলাদেশ সরকার", "dhk": "ঢাকা"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("after import: %v, want %v", got, want)
+	}
+	if err := run("import", "-replace", write("only.json", `{"ctg": "চট্টগ্রাম"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := saved(), (phonetic.Macros{"ctg": "চট্টগ্রাম"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("after import -replace: %v, want %v", got, want)
+	}
+
+	export := filepath.Join(dir, "export.json")
+	if err := run("export", export); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exported, err := parseMacros(data); err != nil || !reflect.DeepEqual(exported, saved()) {
+		t.Errorf("exported %v, %v, want the saved macros", exported, err)
+	}
+}
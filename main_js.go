@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"syscall/js"
+
+	"bengali-keyboard/phonetic"
+)
+
+// main exposes the engine to JavaScript as the global bengaliKeyboard object
+// and keeps the Go runtime alive for its callbacks.
+//
+//	bengaliKeyboard.convert("ami")        // "আমি"
+//	bengaliKeyboard.processKey(event.key) // {backspaces, text, suppress, buffer}
+//	bengaliKeyboard.reset()
+//	bengaliKeyboard.configure({keymap: "avro"}) // null, or an error message
+func main() {
+	keyboardEngine = phonetic.New()
+	session := keyboardEngine.NewSession()
+
+	js.Global().Set("bengaliKeyboard", js.ValueOf(map[string]any{
+		"convert": js.FuncOf(func(this js.Value, args []js.Value) any {
+			if len(args) < 1 {
+				return ""
+			}
+			return keyboardEngine.Convert(args[0].String())
+		}),
+		"processKey": js.FuncOf(func(this js.Value, args []js.Value) any {
+			if len(args) < 1 {
+				return nil
+			}
+			ch := keyToChar(args[0].String())
+			if ch == 0 {
+				return nil
+			}
+
+			edit, suppress := session.Process(ch)
+			return map[string]any{
+				"backspaces": edit.Backspaces,
+				"text":       edit.Text,
+				"suppress":   suppress,
+				"buffer":     session.Buffer(),
+			}
+		}),
+		"reset": js.FuncOf(func(this js.Value, args []js.Value) any {
+			session.Reset()
+			return nil
+		}),
+		"configure": js.FuncOf(func(this js.Value, args []js.Value) any {
+			config := js.Undefined()
+			if len(args) > 0 {
+				config = args[0]
+			}
+			opts, err := engineOptions(config)
+			if err != nil {
+				return err.Error()
+			}
+			// A new session drops the typing buffer of the old engine
+			keyboardEngine = phonetic.New(opts...)
+			session = keyboardEngine.NewSession()
+			return nil
+		}),
+	}))
+
+	select {}
+}
+
+// engineOptions reads the object a page passes to configure. Browsers have
+// no settings file, so its fields are a subset of settings.json's, plus
+// keymap naming a built-in scheme and live for converting after every key.
+// Missing fields keep their defaults.
+func engineOptions(config js.Value) ([]phonetic.Option, error) {
+	if config.IsUndefined() || config.IsNull() {
+		return nil, nil
+	}
+	if config.Type() != js.TypeObject {
+		return nil, errors.New("configure: settings must be an object")
+	}
+
+	keymap := phonetic.NewKeyMap()
+	if name := configString(config, "keymap"); name != "" {
+		var ok bool
+		if keymap, ok = phonetic.LookupKeyMap(name); !ok {
+			return nil, fmt.Errorf("configure: unknown keymap %q", name)
+		}
+	}
+	opts := []phonetic.Option{phonetic.WithKeyMap(keymap)}
+	if configBool(config, "live") {
+		opts = append(opts, phonetic.WithMode(phonetic.ModeLive))
+	}
+
+	digitMode, ok := digitModes[configString(config, "digit_mode")]
+	if !ok {
+		return nil, fmt.Errorf("configure: unknown digit_mode %q", configString(config, "digit_mode"))
+	}
+	normForm, ok := normForms[configString(config, "normalization")]
+	if !ok {
+		return nil, fmt.Errorf("configure: unknown normalization %q", configString(config, "normalization"))
+	}
+	opts = append(opts,
+		phonetic.WithDigitMode(digitMode),
+		phonetic.WithNumberFormatting(configBool(config, "number_formatting")),
+		phonetic.WithNormalization(normForm),
+	)
+
+	if triggers := configString(config, "commit_triggers"); triggers != "" {
+		if err := phonetic.ValidateCommitTriggers(triggers, keymap); err != nil {
+			return nil, fmt.Errorf("configure: commit_triggers: %w", err)
+		}
+		opts = append(opts, phonetic.WithCommitTriggers(triggers))
+	}
+	if configBool(config, "detect_english") {
+		opts = append(opts, phonetic.WithEnglishDetector(phonetic.NewEnglishDetector(phonetic.DefaultEnglishThreshold)))
+	}
+	return opts, nil
+}
+
+// configString returns a string field of a configure object, or "" when
+// it is missing or not a string.
+func configString(config js.Value, name string) string {
+	if v := config.Get(name); v.Type() == js.TypeString {
+		return v.String()
+	}
+	return ""
+}
+
+func configBool(config js.Value, name string) bool {
+	v := config.Get(name)
+	return v.Type() == js.TypeBoolean && v.Bool()
+}
+
+// keyToChar maps a DOM KeyboardEvent.key value to the character the state
+// machine expects, or 0 for keys it does not handle.
+func keyToChar(key string) rune {
+	switch key {
+	case "Backspace":
+		return '\b'
+	case "Enter":
+		return '\n'
+	case "Tab":
+		return '\t'
+	}
+	runes := []rune(key)
+	if len(runes) != 1 {
+		return 0
+	}
+	return runes[0]
+}
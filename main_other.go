@@ -0,0 +1,19 @@
+//go:build !windows && !js
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// The tray keyboard needs the Win32 keyboard hook; elsewhere only the
+// subcommands are available.
+func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
+	fmt.Fprintln(os.Stderr, "The tray keyboard runs on Windows only. Available commands: complete, convert, macro, repl, serve, stats, train")
+	os.Exit(2)
+}
@@ -0,0 +1,720 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"bengali-keyboard/phonetic"
+)
+
+// Windows API constants
+const (
+	WM_USER      = 0x0400
+	WM_TRAYICON  = WM_USER + 1
+	WM_KEYDOWN   = 0x0100
+	WM_KEYUP     = 0x0101
+	WM_COMMAND   = 0x0111
+	WM_DESTROY   = 0x0002
+	WM_RBUTTONUP = 0x0205
+
+	ID_TOGGLE      = 1001
+	ID_EXIT        = 1002
+	ID_SCHEME_BASE = 1100 // plus the index of the scheme in keyboardSchemes.Names
+	TOGGLE_KEY     = 0x79 // F10
+	DATE_KEY       = 0x78 // F9
+	SCHEME_KEY     = 0x77 // F8
+
+	WH_KEYBOARD_LL = 13
+
+	INPUT_KEYBOARD    = 1
+	KEYEVENTF_KEYUP   = 0x0002
+	KEYEVENTF_UNICODE = 0x0004
+
+	VK_BACK    = 0x08
+	VK_TAB     = 0x09
+	VK_RETURN  = 0x0D
+	VK_SHIFT   = 0x10
+	VK_CONTROL = 0x11
+	VK_SPACE   = 0x20
+	VK_F10     = 0x79
+
+	NIF_MESSAGE = 0x00000001
+	NIF_ICON    = 0x00000002
+	NIF_TIP     = 0x00000004
+	NIM_ADD     = 0x00000000
+	NIM_MODIFY  = 0x00000001
+	NIM_DELETE  = 0x00000002
+
+	IDI_APPLICATION = 32512
+	IDC_ARROW       = 32512
+	MF_STRING       = 0x00000000
+	MF_CHECKED      = 0x00000008
+	MF_SEPARATOR    = 0x00000800
+	TPM_RIGHTBUTTON = 0x0002
+
+	sessionIdleTimeout = 30 * time.Minute
+	maxSessions        = 64
+)
+
+// Windows API structures
+type POINT struct {
+	X, Y int32
+}
+
+type MSG struct {
+	Hwnd    syscall.Handle
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	Pt      POINT
+}
+
+type WNDCLASSW struct {
+	Style         uint32
+	LpfnWndProc   uintptr
+	CbClsExtra    int32
+	CbWndExtra    int32
+	HInstance     syscall.Handle
+	HIcon         syscall.Handle
+	HCursor       syscall.Handle
+	HbrBackground syscall.Handle
+	LpszMenuName  *uint16
+	LpszClassName *uint16
+}
+
+type RECT struct {
+	Left, Top, Right, Bottom int32
+}
+
+type GUITHREADINFO struct {
+	CbSize        uint32
+	Flags         uint32
+	HwndActive    syscall.Handle
+	HwndFocus     syscall.Handle
+	HwndCapture   syscall.Handle
+	HwndMenuOwner syscall.Handle
+	HwndMoveSize  syscall.Handle
+	HwndCaret     syscall.Handle
+	RcCaret       RECT
+}
+
+type KBDLLHOOKSTRUCT struct {
+	VkCode      uint32
+	ScanCode    uint32
+	Flags       uint32
+	Time        uint32
+	DwExtraInfo uintptr
+}
+
+type KEYBDINPUT struct {
+	Wvk         uint16
+	Wscan       uint16
+	DwFlags     uint32
+	Time        uint32
+	DwExtraInfo uintptr
+}
+
+type INPUT struct {
+	Type uint32
+	Ki   KEYBDINPUT
+	_    [8]byte // padding for union
+}
+
+type NOTIFYICONDATAW struct {
+	CbSize           uint32
+	Hwnd             syscall.Handle
+	UID              uint32
+	UFlags           uint32
+	UCallbackMessage uint32
+	HIcon            syscall.Handle
+	SzTip            [128]uint16
+	DwState          uint32
+	DwStateMask      uint32
+	SzInfo           [256]uint16
+	UVersion         uint32
+	SzInfoTitle      [64]uint16
+	DwInfoFlags      uint32
+}
+
+type KeyboardState struct {
+	sessions *phonetic.SessionPool
+	focus    uintptr // focus context that received the last key
+	mutex    sync.Mutex
+}
+
+// Global variables
+var (
+	keyboardState = &KeyboardState{} // sessions are set up in main
+
+	mainWindowHandle atomic.Value
+
+	// Windows API DLLs
+	user32   = syscall.NewLazyDLL("user32.dll")
+	kernel32 = syscall.NewLazyDLL("kernel32.dll")
+	shell32  = syscall.NewLazyDLL("shell32.dll")
+
+	// Windows API functions
+	registerClassW      = user32.NewProc("RegisterClassW")
+	createWindowExW     = user32.NewProc("CreateWindowExW")
+	defWindowProcW      = user32.NewProc("DefWindowProcW")
+	getMessageW         = user32.NewProc("GetMessageW")
+	translateMessage    = user32.NewProc("TranslateMessage")
+	dispatchMessageW    = user32.NewProc("DispatchMessageW")
+	postQuitMessage     = user32.NewProc("PostQuitMessage")
+	setWindowsHookExW   = user32.NewProc("SetWindowsHookExW")
+	callNextHookEx      = user32.NewProc("CallNextHookEx")
+	unhookWindowsHookEx = user32.NewProc("UnhookWindowsHookEx")
+	getAsyncKeyState    = user32.NewProc("GetAsyncKeyState")
+	sendInput           = user32.NewProc("SendInput")
+	loadIconW           = user32.NewProc("LoadIconW")
+	loadCursorW         = user32.NewProc("LoadCursorW")
+	createPopupMenu     = user32.NewProc("CreatePopupMenu")
+	appendMenuW         = user32.NewProc("AppendMenuW")
+	getCursorPos        = user32.NewProc("GetCursorPos")
+	setForegroundWindow = user32.NewProc("SetForegroundWindow")
+	trackPopupMenu      = user32.NewProc("TrackPopupMenu")
+	destroyMenu         = user32.NewProc("DestroyMenu")
+	getGUIThreadInfo    = user32.NewProc("GetGUIThreadInfo")
+	getForegroundWindow = user32.NewProc("GetForegroundWindow")
+	getModuleHandleW    = kernel32.NewProc("GetModuleHandleW")
+	shellNotifyIconW    = shell32.NewProc("Shell_NotifyIconW")
+)
+
+func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
+	// Logging depends on the settings, everything else is loaded once
+	// their errors can reach the log file
+	settingsErr := readSettings()
+	logFile := setupLogging()
+	defer logFile.Close()
+
+	slog.Info("Bengali Keyboard starting")
+	if settingsErr != nil {
+		slog.Error("loading settings", "err", settingsErr)
+	}
+	keyboardMetrics = loadKeyboardMetrics()
+	setupEngines()
+	keyboardState.sessions = newKeyboardSessions()
+
+	if err := run(); err != nil {
+		slog.Error("Bengali Keyboard failed", "err", err)
+	}
+
+	if err := saveKeyboardMetrics(); err != nil {
+		slog.Error("saving stats", "err", err)
+	}
+	slog.Info("Bengali Keyboard stopped")
+}
+
+// run sets up the window, hook and tray icon and runs the message loop
+// until the user exits.
+func run() error {
+	hInstance, err := getModuleHandle()
+	if err != nil {
+		return err
+	}
+
+	cursor, err := loadCursor()
+	if err != nil {
+		slog.Warn("loading cursor", "err", err)
+	}
+
+	className := stringToUTF16("BengaliKeyboardClass")
+	wc := WNDCLASSW{
+		LpfnWndProc:   syscall.NewCallback(windowProc),
+		HInstance:     hInstance,
+		HCursor:       cursor,
+		LpszClassName: &className[0],
+	}
+	if err := registerClass(&wc); err != nil {
+		return err
+	}
+
+	windowName := stringToUTF16("Bengali Keyboard")
+	hwnd, err := createWindow(&className[0], &windowName[0], hInstance)
+	if err != nil {
+		return err
+	}
+	mainWindowHandle.Store(hwnd)
+
+	if err := kbdHook.install(hInstance); err != nil {
+		return err
+	}
+	defer func() {
+		if err := kbdHook.uninstall(); err != nil {
+			slog.Warn("removing keyboard hook", "err", err)
+		}
+	}()
+	// Without raw input there is nothing to check the hook against
+	if err := watchKeyboardInput(hwnd); err != nil {
+		slog.Warn("watching keyboard input", "err", err)
+	} else {
+		go kbdHook.watch(hwnd)
+	}
+
+	if err := createTrayIcon(hwnd); err != nil {
+		slog.Error("creating tray icon", "err", err)
+	}
+	slog.Info("Application running")
+
+	go saveMetricsPeriodically()
+
+	var msg MSG
+	for {
+		ok, err := getMessage(&msg)
+		if err != nil {
+			return err
+		}
+		if !ok { // WM_QUIT
+			return nil
+		}
+		translateMessage.Call(uintptr(unsafe.Pointer(&msg)))
+		dispatchMessageW.Call(uintptr(unsafe.Pointer(&msg)))
+	}
+}
+
+func windowProc(hwnd syscall.Handle, msg uint32, wparam, lparam uintptr) uintptr {
+	switch msg {
+	case WM_TRAYICON:
+		if uint32(lparam) == WM_RBUTTONUP {
+			if err := showContextMenu(hwnd); err != nil {
+				slog.Error("showing tray menu", "err", err)
+			}
+		}
+		return 0
+	case WM_INPUT:
+		// Recorded for the hook watchdog; DefWindowProc still has to run
+		kbdHook.input(messageTime())
+	case WM_REINSTALL_HOOK:
+		kbdHook.reinstall()
+		return 0
+	case WM_COMMAND:
+		switch uint32(wparam) & 0xFFFF {
+		case ID_TOGGLE:
+			toggleKeyboard()
+			if err := updateTrayIcon(hwnd); err != nil {
+				slog.Error("updating tray icon", "err", err)
+			}
+		case ID_EXIT:
+			postQuitMessage.Call(0)
+		default:
+			names := keyboardSchemes.Names()
+			if i := int(uint32(wparam)&0xFFFF) - ID_SCHEME_BASE; i >= 0 && i < len(names) {
+				switchScheme(names[i])
+			}
+		}
+		return 0
+	case WM_DESTROY:
+		if err := removeTrayIcon(hwnd); err != nil {
+			slog.Warn("removing tray icon", "err", err)
+		}
+		postQuitMessage.Call(0)
+		return 0
+	}
+	ret, _, _ := defWindowProcW.Call(uintptr(hwnd), uintptr(msg), wparam, lparam)
+	return ret
+}
+
+func keyboardHookProc(code int32, wparam, lparam uintptr) uintptr {
+	if code >= 0 {
+		// lparam points to a struct Windows owns, outside the Go heap. Vet's
+		// unsafeptr check cannot tell and flags this line, so the Windows
+		// build is vetted with go vet -unsafeptr=false.
+		kbdStruct := (*KBDLLHOOKSTRUCT)(unsafe.Pointer(lparam))
+		vkCode := kbdStruct.VkCode
+		kbdHook.seen(kbdStruct.Time)
+
+		// Let the keys of our own edits through untouched
+		if isOwnInput(kbdStruct) {
+			ret, _, _ := callNextHookEx.Call(0, uintptr(code), wparam, lparam)
+			return ret
+		}
+
+		if wparam == WM_KEYDOWN {
+			switchFocus(focusContext())
+		}
+
+		// Check for toggle key (F10)
+		if wparam == WM_KEYDOWN && vkCode == TOGGLE_KEY {
+			toggleKeyboard()
+			refreshTrayIcon()
+			return 1
+		}
+
+		// Cycle through the schemes (F8)
+		if wparam == WM_KEYDOWN && vkCode == SCHEME_KEY {
+			if currentSession().Enabled() {
+				cycleScheme()
+				return 1
+			}
+		}
+
+		// Insert today's date (F9)
+		if wparam == WM_KEYDOWN && vkCode == DATE_KEY {
+			if session := currentSession(); session.Enabled() {
+				session.Reset()
+				applyEdit(phonetic.Edit{Text: phonetic.FormatDate(time.Now(), userSettings.dateFormat())})
+				return 1
+			}
+		}
+
+		// Check for Ctrl key combinations
+		ctrlPressed := isKeyPressed(VK_CONTROL)
+		if ctrlPressed && wparam == WM_KEYDOWN {
+			switch vkCode {
+			case 0x43, 0x56, 0x41, 0x58, 0x5A, 0x59: // Ctrl+C,V,A,X,Z,Y
+				if currentSession().Enabled() {
+					slog.Debug("key", "vk", vkContent(vkCode), "action", "shortcut")
+				}
+				ret, _, _ := callNextHookEx.Call(0, uintptr(code), wparam, lparam)
+				return ret
+			}
+		}
+
+		if wparam == WM_KEYDOWN {
+			if session := currentSession(); session.Enabled() {
+				if ch := vkToChar(vkCode); ch != 0 && processCharacter(session, vkCode, ch) {
+					return 1
+				}
+			}
+		}
+	}
+
+	ret, _, _ := callNextHookEx.Call(0, uintptr(code), wparam, lparam)
+	return ret
+}
+
+func processCharacter(session *phonetic.Session, vkCode uint32, ch rune) bool {
+	start := time.Now()
+	edit, suppress := session.Process(ch)
+	applyEdit(edit)
+	keyboardMetrics.ObserveLatency(time.Since(start))
+
+	if debugEnabled() {
+		traceKey(session, vkCode, ch, edit, suppress)
+	}
+	return suppress
+}
+
+// traceKey logs one step of the key pipeline. Typed text and the virtual
+// key code, which gives the character away as well, are redacted unless
+// log_content is set.
+func traceKey(session *phonetic.Session, vkCode uint32, ch rune, edit phonetic.Edit, suppress bool) {
+	action := "pass"
+	switch {
+	case !edit.IsEmpty():
+		action = "edit"
+	case suppress:
+		action = "suppress"
+	}
+	slog.Debug("key",
+		"vk", vkContent(vkCode),
+		"char", content(string(ch)),
+		"buffer", content(session.Buffer()),
+		"action", action,
+		"backspaces", edit.Backspaces,
+		"text", content(edit.Text),
+	)
+}
+
+// vkContent is a virtual key code in a log record, redacted like typed text.
+func vkContent(vkCode uint32) content {
+	return content(fmt.Sprintf("%#02x", vkCode))
+}
+
+// applyEdit sends the whole edit as one batch of key events, so no key
+// the user types can land in the middle of it.
+func applyEdit(edit phonetic.Edit) {
+	if edit.IsEmpty() {
+		return
+	}
+	if err := sendInputs(editInputs(edit)); err != nil {
+		slog.Error("sending input", "err", err)
+	}
+}
+
+func createTrayIcon(hwnd syscall.Handle) error {
+	var nid NOTIFYICONDATAW
+	nid.CbSize = uint32(unsafe.Sizeof(nid))
+	nid.Hwnd = hwnd
+	nid.UID = 1
+	nid.UFlags = NIF_ICON | NIF_MESSAGE | NIF_TIP
+	nid.UCallbackMessage = WM_TRAYICON
+
+	enabled := currentSession().Enabled()
+
+	icon, err := loadIcon()
+	if err != nil {
+		return err
+	}
+	nid.HIcon = icon
+
+	tooltipUTF16 := stringToUTF16(trayTooltip(enabled))
+	copy(nid.SzTip[:], tooltipUTF16[:min(len(tooltipUTF16), 127)])
+
+	return shellNotifyIcon(NIM_ADD, &nid)
+}
+
+func updateTrayIcon(hwnd syscall.Handle) error {
+	var nid NOTIFYICONDATAW
+	nid.CbSize = uint32(unsafe.Sizeof(nid))
+	nid.Hwnd = hwnd
+	nid.UID = 1
+	nid.UFlags = NIF_ICON | NIF_TIP
+
+	enabled := currentSession().Enabled()
+
+	icon, err := loadIcon()
+	if err != nil {
+		return err
+	}
+	nid.HIcon = icon
+
+	tooltipUTF16 := stringToUTF16(trayTooltip(enabled))
+	copy(nid.SzTip[:], tooltipUTF16[:min(len(tooltipUTF16), 127)])
+
+	return shellNotifyIcon(NIM_MODIFY, &nid)
+}
+
+// trayTooltip names the state and the current scheme. Windows cuts
+// tooltips at 127 characters.
+func trayTooltip(enabled bool) string {
+	state := "Disabled"
+	if enabled {
+		state = "Enabled"
+	}
+	return fmt.Sprintf("Bengali Keyboard - %s, %s (F10 to toggle, F8 to switch scheme)",
+		state, keyboardSchemes.Current().Title)
+}
+
+// refreshTrayIcon updates the tray icon from outside the window procedure,
+// once the window exists.
+func refreshTrayIcon() {
+	if hwnd := mainWindowHandle.Load(); hwnd != nil {
+		if err := updateTrayIcon(hwnd.(syscall.Handle)); err != nil {
+			slog.Error("updating tray icon", "err", err)
+		}
+	}
+}
+
+func removeTrayIcon(hwnd syscall.Handle) error {
+	var nid NOTIFYICONDATAW
+	nid.CbSize = uint32(unsafe.Sizeof(nid))
+	nid.Hwnd = hwnd
+	nid.UID = 1
+
+	return shellNotifyIcon(NIM_DELETE, &nid)
+}
+
+func showContextMenu(hwnd syscall.Handle) error {
+	hmenu, err := createMenu()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := destroyPopupMenu(hmenu); err != nil {
+			slog.Warn("destroying menu", "err", err)
+		}
+	}()
+
+	enabled := currentSession().Enabled()
+
+	var toggleText string
+	if enabled {
+		toggleText = "Disable Bengali Keyboard"
+	} else {
+		toggleText = "Enable Bengali Keyboard"
+	}
+
+	type menuItem struct {
+		flags, id uintptr
+		text      []uint16
+	}
+	items := []menuItem{
+		{MF_STRING, ID_TOGGLE, stringToUTF16(toggleText)},
+		{MF_SEPARATOR, 0, nil},
+	}
+	current := keyboardSchemes.Current()
+	for i, name := range keyboardSchemes.Names() {
+		scheme, _ := keyboardSchemes.Lookup(name)
+		flags := uintptr(MF_STRING)
+		if scheme == current {
+			flags |= MF_CHECKED
+		}
+		items = append(items, menuItem{flags, uintptr(ID_SCHEME_BASE + i), stringToUTF16(scheme.Title)})
+	}
+	items = append(items,
+		menuItem{MF_SEPARATOR, 0, nil},
+		menuItem{MF_STRING, ID_EXIT, stringToUTF16("Exit")},
+	)
+
+	for _, item := range items {
+		if err := appendMenu(hmenu, item.flags, item.id, item.text); err != nil {
+			return err
+		}
+	}
+
+	pt, err := cursorPos()
+	if err != nil {
+		return err
+	}
+
+	// Without the foreground the menu does not close when clicking elsewhere
+	if ret, _, _ := setForegroundWindow.Call(uintptr(hwnd)); ret == 0 {
+		slog.Debug("SetForegroundWindow refused")
+	}
+	return trackMenu(hmenu, pt, hwnd)
+}
+
+// toggleKeyboard flips conversion for the current focus context. Contexts
+// seen for the first time afterwards start in the same state.
+func toggleKeyboard() {
+	session := currentSession()
+	enabled := !session.Enabled()
+	session.SetEnabled(enabled)
+	keyboardState.sessions.SetDefaultEnabled(enabled)
+	slog.Info("conversion toggled", "enabled", enabled)
+}
+
+// cycleScheme switches to the next scheme in the cycling order.
+func cycleScheme() {
+	if scheme := keyboardSchemes.Next(); scheme != nil {
+		useScheme(scheme)
+	}
+}
+
+// switchScheme switches to the scheme chosen in the tray menu.
+func switchScheme(name string) {
+	scheme, err := keyboardSchemes.Switch(name)
+	if err != nil {
+		slog.Error("switching scheme", "err", err)
+		return
+	}
+	useScheme(scheme)
+}
+
+// useScheme converts with the now current scheme from the next key on.
+// Keys are handled on the message loop thread, which also runs this, so
+// no key is converted halfway between two schemes.
+func useScheme(scheme *phonetic.Scheme) {
+	keyboardState.sessions.SetEngine(scheme.Engine())
+	slog.Info("scheme switched", "scheme", scheme.Name)
+	refreshTrayIcon()
+}
+
+func newKeyboardSessions() *phonetic.SessionPool {
+	sessions := keyboardSchemes.Current().Engine().NewSessionPool(sessionIdleTimeout, maxSessions)
+	sessions.SetDefaultEnabled(false)
+	return sessions
+}
+
+// currentSession returns the session of the focus context that received
+// the last key.
+func currentSession() *phonetic.Session {
+	keyboardState.mutex.Lock()
+	focus := keyboardState.focus
+	keyboardState.mutex.Unlock()
+	return keyboardState.sessions.Get(focus)
+}
+
+// switchFocus makes focus the current context. The word being typed in the
+// previous one is dropped, as its caret may have moved by the time it
+// gets the focus back. Contexts are enabled separately, so the tray icon
+// is updated when the new one differs.
+func switchFocus(focus uintptr) {
+	keyboardState.mutex.Lock()
+	previous := keyboardState.focus
+	keyboardState.focus = focus
+	keyboardState.mutex.Unlock()
+
+	if previous == focus {
+		return
+	}
+	previousSession := keyboardState.sessions.Get(previous)
+	previousSession.Reset()
+	if previousSession.Enabled() != keyboardState.sessions.Get(focus).Enabled() {
+		refreshTrayIcon()
+	}
+}
+
+// focusContext identifies the control that has the keyboard focus, falling
+// back to the foreground window.
+func focusContext() uintptr {
+	var info GUITHREADINFO
+	info.CbSize = uint32(unsafe.Sizeof(info))
+	ret, _, err := getGUIThreadInfo.Call(0, uintptr(unsafe.Pointer(&info)))
+	if ret != 0 && info.HwndFocus != 0 {
+		return uintptr(info.HwndFocus)
+	}
+	if ret == 0 {
+		slog.Debug("GetGUIThreadInfo failed", "err", err)
+	}
+	hwnd, _, _ := getForegroundWindow.Call()
+	return hwnd
+}
+
+func vkToChar(vkCode uint32) rune {
+	shiftPressed := isKeyPressed(VK_SHIFT)
+
+	switch {
+	case vkCode >= 0x41 && vkCode <= 0x5A: // A-Z
+		if shiftPressed {
+			return rune(vkCode - 0x41 + 'A')
+		}
+		return rune(vkCode - 0x41 + 'a')
+	case vkCode >= 0x30 && vkCode <= 0x39: // 0-9
+		if shiftPressed {
+			return rune(")!@#$%^&*("[vkCode-0x30])
+		}
+		return rune(vkCode - 0x30 + '0')
+	case vkCode == 0x08:
+		return '\b'
+	case vkCode == 0x20:
+		return ' '
+	case vkCode == 0x0D:
+		return '\n'
+	case vkCode == 0x09:
+		return '\t'
+	}
+
+	// Punctuation keys of the US layout, unshifted and shifted
+	if chars, ok := oemKeyChars[vkCode]; ok {
+		if shiftPressed {
+			return chars[1]
+		}
+		return chars[0]
+	}
+	return 0
+}
+
+var oemKeyChars = map[uint32][2]rune{
+	0xBA: {';', ':'},
+	0xBC: {',', '<'},
+	0xBD: {'-', '_'},
+	0xBE: {'.', '>'},
+	0xBF: {'/', '?'},
+	0xC0: {'`', '~'},
+	0xDB: {'[', '{'},
+	0xDC: {'\\', '|'},
+	0xDD: {']', '}'},
+	0xDE: {'\'', '"'},
+}
+
+func isKeyPressed(vk uint32) bool {
+	ret, _, _ := getAsyncKeyState.Call(uintptr(vk))
+	return (ret & 0x8000) != 0
+}
+
+func stringToUTF16(s string) []uint16 {
+	return syscall.StringToUTF16(s)
+}
@@ -0,0 +1,158 @@
+//go:build !js
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bengali-keyboard/phonetic"
+)
+
+const metricsSaveInterval = 5 * time.Minute
+
+// keyboardMetrics is nil unless the tray keyboard loaded it with metrics
+// enabled in the settings. It saves them to stats.json in the
+// configuration directory.
+var keyboardMetrics *phonetic.Metrics
+
+func metricsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "stats.json"), nil
+}
+
+func loadMetricsSnapshot() (phonetic.MetricsSnapshot, error) {
+	var snapshot phonetic.MetricsSnapshot
+
+	path, err := metricsPath()
+	if err != nil {
+		return snapshot, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, err
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("stats.json: %w", err)
+	}
+	return snapshot, nil
+}
+
+func loadKeyboardMetrics() *phonetic.Metrics {
+	if !userSettings.Metrics {
+		return nil
+	}
+
+	metrics := phonetic.NewMetrics()
+	snapshot, err := loadMetricsSnapshot()
+	if err == nil {
+		metrics.Restore(snapshot)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		slog.Error("loading stats", "err", err)
+	}
+	return metrics
+}
+
+func saveKeyboardMetrics() error {
+	if keyboardMetrics == nil {
+		return nil
+	}
+
+	path, err := metricsPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(keyboardMetrics.Snapshot(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// saveMetricsPeriodically keeps stats.json current while the keyboard runs.
+func saveMetricsPeriodically() {
+	if keyboardMetrics == nil {
+		return
+	}
+	for range time.Tick(metricsSaveInterval) {
+		if err := saveKeyboardMetrics(); err != nil {
+			slog.Error("saving stats", "err", err)
+		}
+	}
+}
+
+// runStats prints the metrics saved by the tray keyboard.
+func runStats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	top := flags.Int("top", 10, "number of most used patterns to show")
+	reset := flags.Bool("reset", false, "delete the saved statistics")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *reset {
+		path, err := metricsPath()
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	snapshot, err := loadMetricsSnapshot()
+	if errors.Is(err, fs.ErrNotExist) {
+		return errors.New(`no statistics recorded; enable them with "metrics": true in settings.json`)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Converted words:      %d\n", snapshot.ConvertedWords)
+	fmt.Printf("Undone conversions:   %d (%.1f%%)\n", snapshot.Undos, 100*snapshot.UndoRate())
+	fmt.Printf("Average key handling: %v over %d keys\n", snapshot.AverageLatency(), snapshot.LatencyCount)
+	fmt.Println("Most used patterns:")
+	for _, pc := range snapshot.TopPatterns(*top) {
+		fmt.Printf("  %-6s %d\n", pc.Pattern, pc.Count)
+	}
+	return nil
+}
+
+// writePrometheus renders metrics in the Prometheus text exposition format.
+func writePrometheus(w io.Writer, snapshot phonetic.MetricsSnapshot) {
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP bengali_keyboard_%s %s\n# TYPE bengali_keyboard_%s %s\n", name, help, name, kind)
+	}
+
+	metric("converted_words_total", "counter", "Words converted to Bengali.")
+	fmt.Fprintf(w, "bengali_keyboard_converted_words_total %d\n", snapshot.ConvertedWords)
+
+	metric("undos_total", "counter", "Conversions erased right after they were made.")
+	fmt.Fprintf(w, "bengali_keyboard_undos_total %d\n", snapshot.Undos)
+
+	metric("key_handling_seconds", "summary", "Time spent handling a key in the keyboard hook.")
+	fmt.Fprintf(w, "bengali_keyboard_key_handling_seconds_sum %g\n", snapshot.LatencyTotal.Seconds())
+	fmt.Fprintf(w, "bengali_keyboard_key_handling_seconds_count %d\n", snapshot.LatencyCount)
+
+	metric("pattern_hits_total", "counter", "Keymap patterns used in converted words.")
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	for _, pc := range snapshot.TopPatterns(0) {
+		fmt.Fprintf(w, "bengali_keyboard_pattern_hits_total{pattern=\"%s\"} %d\n", escaper.Replace(pc.Pattern), pc.Count)
+	}
+}
@@ -0,0 +1,86 @@
+package phonetic
+
+// Autocorrect maps words to their preferred spelling. A key is either the
+// Latin word as typed or its literal conversion, so "bangladesh" and
+// "কারন" both work as keys.
+type Autocorrect map[string]string
+
+// Words that phonetic typing reliably gets wrong.
+var defaultAutocorrect = Autocorrect{
+	"bangladesh":  "বাংলাদেশ",
+	"dhaka":       "ঢাকা",
+	"bhalobasha":  "ভালোবাসা",
+	"dhonnobad":   "ধন্যবাদ",
+	"jonno":       "জন্য",
+	"shomoy":      "সময়",
+	"shundor":     "সুন্দর",
+	"shubho":      "শুভ",
+	"bondhu":      "বন্ধু",
+	"shadhinota":  "স্বাধীনতা",
+	"shorkar":     "সরকার",
+	"shongbidhan": "সংবিধান",
+	"rastro":      "রাষ্ট্র",
+	"ekushe":      "একুশে",
+	"phebruari":   "ফেব্রুয়ারি",
+	"school":      "স্কুল",
+	"computer":    "কম্পিউটার",
+	"internet":    "ইন্টারনেট",
+	"facebook":    "ফেসবুক",
+	"mobile":      "মোবাইল",
+	"কারন":        "কারণ",
+	"পরিক্ষা":     "পরীক্ষা",
+	"ষহর":         "শহর",
+	"ভাল":         "ভালো",
+}
+
+// DefaultAutocorrect returns a copy of the built-in autocorrect table, to
+// be extended or trimmed before use.
+func DefaultAutocorrect() Autocorrect {
+	table := make(Autocorrect, len(defaultAutocorrect))
+	for word, preferred := range defaultAutocorrect {
+		table[word] = preferred
+	}
+	return table
+}
+
+// WithAutocorrect makes sessions replace committed words found in table
+// with their preferred spelling. A backspace right after the correction
+// brings back the literal conversion.
+func WithAutocorrect(table Autocorrect) Option {
+	return func(e *Engine) {
+		e.autocorrect = table
+	}
+}
+
+// normalizeAutocorrect returns a copy of table with its Bengali keys in
+// form, the form conversions come out in, so a key written precomposed
+// still matches.
+func normalizeAutocorrect(table Autocorrect, form NormForm) Autocorrect {
+	if table == nil {
+		return nil
+	}
+	normalized := make(Autocorrect, len(table))
+	for word, preferred := range table {
+		normalized[Normalize(word, form)] = preferred
+	}
+	return normalized
+}
+
+// corrected returns the preferred spelling of a word typed as word and
+// converted to bengali, or bengali itself.
+func (e *Engine) corrected(word, bengali string) string {
+	if preferred, ok := e.autocorrect[word]; ok {
+		return Normalize(preferred, e.normForm)
+	}
+	if preferred, ok := e.autocorrect[bengali]; ok {
+		return Normalize(preferred, e.normForm)
+	}
+	return bengali
+}
+
+// correction is an autocorrection a session can still take back.
+type correction struct {
+	literal   string // the plain conversion
+	corrected string
+	trailing  string // punctuation typed after it
+}
@@ -0,0 +1,151 @@
+package phonetic
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// BanglaDate is a date in the Bangla calendar (বঙ্গাব্দ) as revised in
+// Bangladesh in 2019: the year starts on 14 April, the first six months
+// have 31 days, ফাল্গুন has 29 days or 30 in a Gregorian leap year and the
+// other months 30.
+type BanglaDate struct {
+	Year  int
+	Month int // 1 is বৈশাখ
+	Day   int
+}
+
+var banglaMonths = [...]string{
+	"বৈশাখ", "জ্যৈষ্ঠ", "আষাঢ়", "শ্রাবণ", "ভাদ্র", "আশ্বিন",
+	"কার্তিক", "অগ্রহায়ণ", "পৌষ", "মাঘ", "ফাল্গুন", "চৈত্র",
+}
+
+// Seasons (ঋতু) of two months each, starting with বৈশাখ.
+var banglaSeasons = [...]string{"গ্রীষ্ম", "বর্ষা", "শরৎ", "হেমন্ত", "শীত", "বসন্ত"}
+
+var gregorianMonths = [...]string{
+	"জানুয়ারি", "ফেব্রুয়ারি", "মার্চ", "এপ্রিল", "মে", "জুন",
+	"জুলাই", "আগস্ট", "সেপ্টেম্বর", "অক্টোবর", "নভেম্বর", "ডিসেম্বর",
+}
+
+var bengaliWeekdays = [...]string{
+	"রবিবার", "সোমবার", "মঙ্গলবার", "বুধবার", "বৃহস্পতিবার", "শুক্রবার", "শনিবার",
+}
+
+// Bangla year in which a Gregorian year's 14 April falls.
+const banglaYearOffset = 593
+
+// ToBanglaDate converts the calendar date of t, in t's location.
+func ToBanglaDate(t time.Time) BanglaDate {
+	year := t.Year()
+	start := time.Date(year, time.April, 14, 0, 0, 0, 0, time.UTC)
+	day := time.Date(year, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Before(start) {
+		year--
+		start = time.Date(year, time.April, 14, 0, 0, 0, 0, time.UTC)
+	}
+
+	// ফাল্গুন falls in February of the following Gregorian year
+	days := int(day.Sub(start).Hours() / 24)
+	for month := 1; ; month++ {
+		length := banglaMonthLength(month, isLeapYear(year+1))
+		if days < length {
+			return BanglaDate{Year: year - banglaYearOffset, Month: month, Day: days + 1}
+		}
+		days -= length
+	}
+}
+
+func banglaMonthLength(month int, leap bool) int {
+	switch {
+	case month <= 6:
+		return 31
+	case month == 11 && !leap:
+		return 29
+	}
+	return 30
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// MonthName returns the Bengali name of the month, e.g. কার্তিক.
+func (d BanglaDate) MonthName() string {
+	return banglaMonths[d.Month-1]
+}
+
+// Season returns the Bengali name of the season, two months each.
+func (d BanglaDate) Season() string {
+	return banglaSeasons[(d.Month-1)/2]
+}
+
+// String writes the date the usual way, e.g. ২ কার্তিক ১৪৩৩ বঙ্গাব্দ.
+func (d BanglaDate) String() string {
+	return toBengaliDigits(fmt.Sprintf("%d %s %d বঙ্গাব্দ", d.Day, d.MonthName(), d.Year))
+}
+
+// Fields of a date pattern. Numbers are written with Bengali digits.
+var dateFields = map[string]func(time.Time) string{
+	// Gregorian
+	"d":     func(t time.Time) string { return bengaliNumber(t.Day(), 1) },
+	"dd":    func(t time.Time) string { return bengaliNumber(t.Day(), 2) },
+	"mm":    func(t time.Time) string { return bengaliNumber(int(t.Month()), 2) },
+	"month": func(t time.Time) string { return gregorianMonths[t.Month()-1] },
+	"yyyy":  func(t time.Time) string { return bengaliNumber(t.Year(), 4) },
+	"day":   func(t time.Time) string { return bengaliWeekdays[t.Weekday()] },
+	"time":  func(t time.Time) string { return toBengaliDigits(t.Format("15:04")) },
+
+	// Bangla calendar
+	"bd":     func(t time.Time) string { return bengaliNumber(ToBanglaDate(t).Day, 1) },
+	"bmonth": func(t time.Time) string { return ToBanglaDate(t).MonthName() },
+	"byear":  func(t time.Time) string { return bengaliNumber(ToBanglaDate(t).Year, 1) },
+	"season": func(t time.Time) string { return ToBanglaDate(t).Season() },
+
+	// Whole dates
+	"date": func(t time.Time) string { return toBengaliDigits(t.Format("02/01/2006")) },
+	"date_long": func(t time.Time) string {
+		return toBengaliDigits(fmt.Sprintf("%d %s %d", t.Day(), gregorianMonths[t.Month()-1], t.Year()))
+	},
+	"bangla_date": func(t time.Time) string { return ToBanglaDate(t).String() },
+}
+
+var placeholderPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// FormatDate fills the {field} placeholders of pattern with parts of t:
+//
+//	{d} {dd}       day of the month, ৮ or ০৮
+//	{mm} {month}   month, ১০ or অক্টোবর
+//	{yyyy}         year
+//	{day}          day of the week, রবিবার
+//	{time}         time of day, ১৪:০৫
+//	{bd} {bmonth} {byear} {season}
+//	               day, month, year and season (ঋতু) of the Bangla calendar
+//	{date}         ১৮/১০/২০২৬
+//	{date_long}    ১৮ অক্টোবর ২০২৬
+//	{bangla_date}  ২ কার্তিক ১৪৩৩ বঙ্গাব্দ
+//
+// Unknown placeholders are left as they are.
+func FormatDate(t time.Time, pattern string) string {
+	return placeholderPattern.ReplaceAllStringFunc(pattern, func(placeholder string) string {
+		if field, ok := dateFields[placeholder[1:len(placeholder)-1]]; ok {
+			return field(t)
+		}
+		return placeholder
+	})
+}
+
+// ValidateDatePattern reports the first unknown placeholder in pattern.
+func ValidateDatePattern(pattern string) error {
+	for _, match := range placeholderPattern.FindAllStringSubmatch(pattern, -1) {
+		if _, ok := dateFields[match[1]]; !ok {
+			return fmt.Errorf("unknown placeholder %s", match[0])
+		}
+	}
+	return nil
+}
+
+func bengaliNumber(n, width int) string {
+	return toBengaliDigits(fmt.Sprintf("%0*d", width, n))
+}
@@ -0,0 +1,50 @@
+package phonetic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestToBanglaDate(t *testing.T) {
+	tests := []struct {
+		date string
+		want BanglaDate
+	}{
+		// Pohela Boishakh
+		{"2025-04-13", BanglaDate{1431, 12, 30}},
+		{"2025-04-14", BanglaDate{1432, 1, 1}},
+		{"2025-12-31", BanglaDate{1432, 9, 16}},
+		{"2026-01-01", BanglaDate{1432, 9, 17}},
+
+		// The last 31-day month and the first 30-day one
+		{"2025-09-16", BanglaDate{1432, 6, 1}},
+		{"2025-10-16", BanglaDate{1432, 6, 31}},
+		{"2025-10-17", BanglaDate{1432, 7, 1}},
+
+		// ফাল্গুন has 30 days in a leap year, 29 otherwise
+		{"2024-02-14", BanglaDate{1430, 11, 1}},
+		{"2024-03-14", BanglaDate{1430, 11, 30}},
+		{"2024-03-15", BanglaDate{1430, 12, 1}},
+		{"2025-03-14", BanglaDate{1431, 11, 29}},
+		{"2025-03-15", BanglaDate{1431, 12, 1}},
+	}
+	for _, tt := range tests {
+		day, err := time.Parse(time.DateOnly, tt.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ToBanglaDate(day); got != tt.want {
+			t.Errorf("ToBanglaDate(%s) = %+v, want %+v", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestBanglaDateString(t *testing.T) {
+	d := BanglaDate{Year: 1432, Month: 1, Day: 1}
+	if got, want := d.String(), "১ বৈশাখ ১৪৩২ বঙ্গাব্দ"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := d.Season(), "গ্রীষ্ম"; got != want {
+		t.Errorf("Season() = %q, want %q", got, want)
+	}
+}
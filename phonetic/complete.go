@@ -0,0 +1,110 @@
+package phonetic
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Letters typed before a session offers completions.
+const minCompletionLetters = 3
+
+// Completer finds the words whose completion key, see CompletionKey,
+// starts with a prefix, most frequent first. predict.Index is one.
+type Completer interface {
+	Complete(prefix string, limit int) []string
+}
+
+// WithCompleter makes sessions of the engine complete long words from the
+// first few letters typed.
+func WithCompleter(completer Completer) Option {
+	return func(e *Engine) {
+		e.completer = completer
+	}
+}
+
+// CompletionKey is the key a Bengali word is completed by: LatinKey of the
+// input that types it.
+func (e *Engine) CompletionKey(word string) string {
+	return LatinKey(e.Reverse(word))
+}
+
+// Letters typed for the same sound, and the one LatinKey keeps.
+var latinKeyLetters = map[rune]rune{'w': 'b', 'v': 'b', 'f': 'p', 'q': 'k'}
+
+// LatinKey reduces phonetic input to its consonant skeleton, so different
+// spellings of a word, such as bishwobiddaloy and biSbobidzaloy, share a
+// key and each letter typed extends it. Case, vowels, y and z, an h after
+// a consonant and doubled letters are dropped.
+func LatinKey(latin string) string {
+	key := make([]byte, 0, len(latin))
+	var last rune      // last letter kept
+	afterVowel := true // no consonant typed since the last vowel
+	for _, ch := range latin {
+		if ch >= 'A' && ch <= 'Z' {
+			ch += 'a' - 'A'
+		}
+		if ch < 'a' || ch > 'z' {
+			continue
+		}
+		switch ch {
+		case 'a', 'e', 'i', 'o', 'u', 'y', 'z':
+			afterVowel = true
+			continue
+		case 'h':
+			if !afterVowel {
+				continue
+			}
+		}
+		if mapped, ok := latinKeyLetters[ch]; ok {
+			ch = mapped
+		}
+		if ch != last || afterVowel {
+			key = append(key, byte(ch))
+		}
+		last = ch
+		afterVowel = false
+	}
+	return string(key)
+}
+
+// Completions returns words that complete the Latin word being typed:
+// matching words committed recently in the session, latest first, then
+// the completer's. There are none before minCompletionLetters letters or
+// without a completer.
+func (s *Session) Completions(limit int) []string {
+	s.mutex.Lock()
+	engine := s.engine
+	buffer := s.inputBuffer
+	history := append([]string(nil), s.history...)
+	s.mutex.Unlock()
+
+	if engine.completer == nil || utf8.RuneCountInString(buffer) < minCompletionLetters {
+		return nil
+	}
+	key := LatinKey(buffer)
+	if key == "" {
+		return nil
+	}
+
+	var words []string
+	seen := make(map[string]bool)
+	add := func(word string) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		if strings.HasPrefix(engine.CompletionKey(history[i]), key) {
+			add(history[i])
+		}
+	}
+	for _, word := range engine.completer.Complete(key, limit) {
+		add(word)
+	}
+
+	if limit > 0 && len(words) > limit {
+		words = words[:limit]
+	}
+	return words
+}
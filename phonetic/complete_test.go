@@ -0,0 +1,39 @@
+package phonetic
+
+import "testing"
+
+func TestLatinKey(t *testing.T) {
+	tests := []struct {
+		latin string
+		want  string
+	}{
+		{"", ""},
+		{"aeiou", ""},
+		{"ami", "m"},
+		{"Ami", "m"},
+		{"bishwobiddaloy", "bsbbdl"},
+		{"biSbobidzaloy", "bsbbdl"},
+		{"kotha", "kt"},
+		{"hat", "ht"},
+		{"vhalo", "bl"},
+		{"fol", "pl"},
+		{"qolom", "klm"},
+		{"ammu", "m"},
+		{"mama", "mm"},
+		{"k123,", "k"},
+	}
+	for _, tt := range tests {
+		if got := LatinKey(tt.latin); got != tt.want {
+			t.Errorf("LatinKey(%q) = %q, want %q", tt.latin, got, tt.want)
+		}
+	}
+
+	// Typing extends the key of what was typed before
+	word := "bishwobiddaloy"
+	for n := 1; n < len(word); n++ {
+		prefix, whole := LatinKey(word[:n]), LatinKey(word)
+		if len(prefix) > len(whole) || whole[:len(prefix)] != prefix {
+			t.Errorf("LatinKey(%q) = %q is not a prefix of %q", word[:n], prefix, whole)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+// Package phonetic transliterates Latin phonetic input to Bengali, either a
+// whole text at once with Engine.Convert or keystroke by keystroke with a
+// Session.
+package phonetic
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mode selects when typed Latin text is replaced by Bengali.
+type Mode int
+
+const (
+	// ModeWord converts the buffered word when a word boundary is typed.
+	ModeWord Mode = iota
+	// ModeLive replaces the word with its conversion after every keystroke.
+	ModeLive
+)
+
+// Engine converts Latin phonetic input to Bengali. It is immutable once
+// created and safe for concurrent use; typing state lives in Sessions.
+type Engine struct {
+	keymap    *KeyMap
+	reverse   *reverseMap
+	mode      Mode
+	triggers  map[rune]bool
+	english   *EnglishDetector
+	metrics   *Metrics
+	macros    Macros
+	spell     SpellChecker
+	predictor Predictor
+	completer Completer
+
+	autocorrect Autocorrect // preferred spellings applied on commit
+
+	// Characters used by keymap patterns besides isValidInputChar's
+	inputChars map[rune]bool
+	// Every prefix of an expansion token
+	expansionPrefixes map[string]bool
+
+	digitMode     DigitMode
+	formatNumbers bool
+	normForm      NormForm
+}
+
+// EscapeChar encloses text that is kept as typed, such as an English word
+// in a Bengali sentence. Text after an unclosed escape is kept up to the end
+// of the line, and two escape characters in a row give a literal one.
+const EscapeChar = '`'
+
+// Punctuation that ends a word the way a space does, unless changed with
+// WithCommitTriggers.
+const DefaultCommitTriggers = ",;?!\"')]}|"
+
+// Option configures an Engine.
+type Option func(*Engine)
+
+// WithKeyMap makes the engine use keymap instead of the default one.
+func WithKeyMap(keymap *KeyMap) Option {
+	return func(e *Engine) {
+		e.keymap = keymap
+	}
+}
+
+// WithMode sets when sessions of the engine convert typed text.
+func WithMode(mode Mode) Option {
+	return func(e *Engine) {
+		e.mode = mode
+	}
+}
+
+// WithCommitTriggers sets the characters besides space, tab and newline
+// that convert the pending word before they are typed.
+func WithCommitTriggers(chars string) Option {
+	return func(e *Engine) {
+		e.triggers = make(map[rune]bool)
+		for _, ch := range chars {
+			e.triggers[ch] = true
+		}
+	}
+}
+
+// ValidateCommitTriggers reports the first of chars that cannot end words
+// typed with keymap: one typed inside words, or EscapeChar.
+func ValidateCommitTriggers(chars string, keymap *KeyMap) error {
+	e := New(WithKeyMap(keymap))
+	for _, ch := range chars {
+		if ch == EscapeChar {
+			return fmt.Errorf("%q starts escaped text", ch)
+		}
+		if e.isInputChar(ch) {
+			return fmt.Errorf("%q would end words in the middle", ch)
+		}
+	}
+	return nil
+}
+
+// WithEnglishDetector leaves words that detector takes for English
+// unconverted when sessions commit them.
+func WithEnglishDetector(detector *EnglishDetector) Option {
+	return func(e *Engine) {
+		e.english = detector
+	}
+}
+
+// New returns an engine with the default keymap in ModeWord, changed by
+// opts.
+func New(opts ...Option) *Engine {
+	e := &Engine{mode: ModeWord}
+	WithCommitTriggers(DefaultCommitTriggers)(e)
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.keymap == nil {
+		e.keymap = NewKeyMap()
+	}
+	e.reverse = newReverseMap(e.keymap)
+	e.inputChars = make(map[rune]bool)
+	for pattern := range e.keymap.Patterns {
+		for _, ch := range pattern {
+			e.inputChars[ch] = true
+		}
+	}
+	e.expansionPrefixes = expansionPrefixes(e.keymap.Expansions, e.macros, dateMacros)
+	e.autocorrect = normalizeAutocorrect(e.autocorrect, e.normForm)
+	return e
+}
+
+// isInputChar reports whether ch belongs to a word being typed.
+func (e *Engine) isInputChar(ch rune) bool {
+	return isValidInputChar(ch) || e.inputChars[ch]
+}
+
+// Mode returns when sessions of the engine convert typed text.
+func (e *Engine) Mode() Mode {
+	return e.mode
+}
+
+// isEnglish reports whether a typed word should stay as it is.
+func (e *Engine) isEnglish(word string) bool {
+	return e.english != nil && e.english.IsEnglish(word)
+}
+
+// isCommitTrigger reports whether ch ends the word being typed.
+func (e *Engine) isCommitTrigger(ch rune) bool {
+	return ch == ' ' || ch == '\n' || ch == '\t' || e.triggers[ch]
+}
+
+// punctuation returns what the scheme types for a commit trigger.
+func (e *Engine) punctuation(ch rune) string {
+	if bengali, ok := e.keymap.Punctuation[string(ch)]; ok {
+		return bengali
+	}
+	return string(ch)
+}
+
+// PatternMatch records one keymap pattern consumed while converting,
+// together with the Bengali text it produced at that position. Numbers and
+// expansions are recorded too, with the text typed as their Pattern.
+type PatternMatch struct {
+	Pos     int
+	Pattern string
+	Output  string
+	Kind    MatchKind
+}
+
+// MatchKind tells what a PatternMatch matched.
+type MatchKind int
+
+const (
+	MatchPattern   MatchKind = iota // a keymap pattern
+	MatchNumber                     // a number, converted digit by digit
+	MatchExpansion                  // a whole token expansion
+)
+
+// Convert transliterates Latin input to Bengali. Words the English
+// detector takes for English are kept as typed, as sessions keep them.
+func (e *Engine) Convert(input string) string {
+	return e.convert(input, true, nil)
+}
+
+// ConvertTrace is Convert that also reports which patterns matched.
+func (e *Engine) ConvertTrace(input string) (string, []PatternMatch) {
+	var matches []PatternMatch
+	output := e.convert(input, true, func(m PatternMatch) {
+		matches = append(matches, m)
+	})
+	return output, matches
+}
+
+// convert transliterates input, leaving English words as typed when
+// keepEnglish is set. Sessions showing a word as it is typed convert it
+// regardless, since whether it is English is only decided on commit.
+func (e *Engine) convert(input string, keepEnglish bool, trace func(PatternMatch)) string {
+	var result strings.Builder
+	chars := []rune(input)
+	i := 0
+
+	for i < len(chars) {
+		if chars[i] == EscapeChar {
+			end := i + 1
+			for end < len(chars) && chars[end] != EscapeChar && chars[end] != '\n' {
+				end++
+			}
+			closed := end < len(chars) && chars[end] == EscapeChar
+			if closed && end == i+1 {
+				result.WriteRune(EscapeChar)
+			} else {
+				result.WriteString(string(chars[i+1 : end]))
+			}
+			if closed {
+				end++
+			}
+			i = end
+			continue
+		}
+
+		if keepEnglish && e.english != nil && (i == 0 || !e.isInputChar(chars[i-1])) {
+			end := i
+			for end < len(chars) && e.isInputChar(chars[end]) {
+				end++
+			}
+			if word := string(chars[i:end]); e.isEnglish(word) {
+				result.WriteString(word)
+				i = end
+				continue
+			}
+		}
+
+		if output, n := e.matchExpansion(chars, i); n > 0 {
+			result.WriteString(output)
+			if trace != nil {
+				trace(PatternMatch{Pos: i, Pattern: string(chars[i : i+n]), Output: output, Kind: MatchExpansion})
+			}
+			i += n
+			continue
+		}
+
+		if num, n := matchNumber(chars, i); n > 0 {
+			output := e.formatNumber(num)
+			result.WriteString(output)
+			if trace != nil {
+				trace(PatternMatch{Pos: i, Pattern: string(chars[i : i+n]), Output: output, Kind: MatchNumber})
+			}
+			i += n
+			continue
+		} else if isASCIIDigit(chars[i]) && e.digitMode != DigitsBengali {
+			result.WriteRune(chars[i])
+			i++
+			continue
+		}
+
+		longestMatch := ""
+		longestBengali := ""
+		longestLen := 0
+		isVowel := false
+
+		// Try to find the longest matching pattern
+		for pattern, bengaliChar := range e.keymap.Patterns {
+			patternRunes := []rune(pattern)
+			if i+len(patternRunes) <= len(chars) {
+				slice := string(chars[i : i+len(patternRunes)])
+				if slice == pattern && len(patternRunes) > longestLen {
+					longestMatch = pattern
+					longestBengali = bengaliChar.Bengali
+					longestLen = len(patternRunes)
+					isVowel = bengaliChar.IsVowel
+				}
+			}
+		}
+
+		if longestLen > 0 {
+			written := result.Len()
+
+			// Special handling for vowels
+			if isVowel {
+				resultStr := result.String()
+				if len(resultStr) > 0 && endsWithConsonant(resultStr) {
+					if longestMatch == "o" {
+						// "o" after consonant is inherent vowel - add nothing
+					} else if diacritic, exists := e.keymap.VowelDiacritics[longestMatch]; exists {
+						result.WriteString(diacritic)
+					} else {
+						result.WriteString(longestBengali)
+					}
+				} else {
+					// Independent vowel
+					result.WriteString(longestBengali)
+				}
+			} else {
+				// Consonant or other character
+				result.WriteString(longestBengali)
+			}
+			if trace != nil {
+				trace(PatternMatch{
+					Pos:     i,
+					Pattern: longestMatch,
+					Output:  result.String()[written:],
+				})
+			}
+			i += longestLen
+		} else if e.isCommitTrigger(chars[i]) {
+			result.WriteString(e.punctuation(chars[i]))
+			i++
+		} else {
+			result.WriteRune(chars[i])
+			i++
+		}
+	}
+
+	return Normalize(result.String(), e.normForm)
+}
+
+func endsWithConsonant(text string) bool {
+	text = trimNukta(text)
+	if len(text) == 0 {
+		return false
+	}
+	runes := []rune(text)
+	lastChar := runes[len(runes)-1]
+	return isBengaliConsonant(lastChar)
+}
+
+func isBengaliConsonant(ch rune) bool {
+	return (ch >= '\u0995' && ch <= '\u09B9') || // ক to হ
+		ch == '\u09DC' || ch == '\u09DD' || // ড় and ঢ়
+		ch == '\u09DF' || // য়
+		ch == '\u09CE' // ৎ
+}
@@ -0,0 +1,66 @@
+package phonetic
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestConvertEscapes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"a`b`c", "আbচ"},
+		{"ami `hello` tumi", "আমি hello তুমি"},
+		{"``", "`"},
+		{"ami `` tumi", "আমি ` তুমি"},
+		{"ami `hello tumi", "আমি hello tumi"},
+		{"`hello\ntumi", "hello\nতুমি"},
+		{"`a`b`c`", "aবc"},
+	}
+	engine := New()
+	for _, tt := range tests {
+		if got := engine.Convert(tt.input); got != tt.want {
+			t.Errorf("Convert(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestValidateCommitTriggers(t *testing.T) {
+	tests := []struct {
+		chars string
+		want  string // error, empty when valid
+	}{
+		{DefaultCommitTriggers, ""},
+		{"", ""},
+		{",;-", ""},
+
+		// Typed inside words by every scheme
+		{",a", `'a' would end words in the middle`},
+		{"5", `'5' would end words in the middle`},
+		{".", `'.' would end words in the middle`},
+		{":", `':' would end words in the middle`},
+		{"$", `'$' would end words in the middle`},
+		{"_", `'_' would end words in the middle`},
+
+		// Typed inside words by the scheme's patterns
+		{"^", `'^' would end words in the middle`},
+		{"~", `'~' would end words in the middle`},
+
+		{"`", "'`' starts escaped text"},
+	}
+	for _, tt := range tests {
+		err := ValidateCommitTriggers(tt.chars, NewKeyMap())
+		if got := fmt.Sprint(err); (tt.want == "" && err != nil) || (tt.want != "" && got != tt.want) {
+			t.Errorf("ValidateCommitTriggers(%q) = %v, want %q", tt.chars, err, tt.want)
+		}
+	}
+
+	// + is only part of avro's patterns
+	if err := ValidateCommitTriggers("+", NewKeyMap()); err != nil {
+		t.Errorf("ValidateCommitTriggers(+) with the phonetic keymap = %v", err)
+	}
+	if err := ValidateCommitTriggers("+", NewAvroKeyMap()); err == nil {
+		t.Error("ValidateCommitTriggers(+) with the avro keymap succeeded")
+	}
+}
@@ -0,0 +1,148 @@
+package phonetic
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Default score above which a word is taken to be English.
+const DefaultEnglishThreshold = 0.7
+
+// EnglishDetector guesses whether a typed Latin word is English rather than
+// phonetic Bengali, so that it can be left unconverted. It scores words with
+// an English lexicon and letter bigram models of English and of phonetically
+// typed Bengali. It is safe for concurrent use.
+type EnglishDetector struct {
+	threshold  float64
+	lexicon    map[string]bool
+	bengali    map[string]bool
+	english    *letterModel
+	phonetic   *letterModel
+	exceptions map[string]bool // words the user always wants converted
+	mutex      sync.RWMutex
+}
+
+// NewEnglishDetector returns a detector taking words that score above
+// threshold for English.
+func NewEnglishDetector(threshold float64) *EnglishDetector {
+	d := &EnglishDetector{
+		threshold:  threshold,
+		lexicon:    make(map[string]bool),
+		bengali:    make(map[string]bool),
+		english:    newLetterModel(),
+		phonetic:   newLetterModel(),
+		exceptions: make(map[string]bool),
+	}
+	for _, word := range strings.Fields(englishWords) {
+		d.lexicon[word] = true
+		d.english.train(word)
+	}
+	for _, word := range strings.Fields(bengaliWords) {
+		d.bengali[word] = true
+		d.phonetic.train(word)
+	}
+	return d
+}
+
+// Threshold returns the score above which words count as English.
+func (d *EnglishDetector) Threshold() float64 {
+	return d.threshold
+}
+
+// AddException makes word always count as Bengali.
+func (d *EnglishDetector) AddException(word string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.exceptions[strings.ToLower(word)] = true
+}
+
+// RemoveException undoes AddException.
+func (d *EnglishDetector) RemoveException(word string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.exceptions, strings.ToLower(word))
+}
+
+// Score returns how likely word is English, from 0 (Bengali) to 1 (English).
+func (d *EnglishDetector) Score(word string) float64 {
+	// The phonetic scheme gives capitals inside a word their own meaning
+	// (kaTa, baDi), and digits and punctuation have no place in English words
+	for i, ch := range word {
+		if ch > unicode.MaxASCII || !unicode.IsLetter(ch) || (i > 0 && unicode.IsUpper(ch)) {
+			return 0
+		}
+	}
+
+	lower := strings.ToLower(word)
+	if lower == "" {
+		return 0
+	}
+
+	d.mutex.RLock()
+	exception := d.exceptions[lower]
+	d.mutex.RUnlock()
+	if exception || d.bengali[lower] {
+		return 0
+	}
+	if d.lexicon[lower] {
+		return 1
+	}
+
+	// Average log likelihood ratio per letter, squashed into 0..1
+	ratio := (d.english.logProb(lower) - d.phonetic.logProb(lower)) / float64(len(lower)+1)
+	return 1 / (1 + math.Exp(-4*ratio))
+}
+
+// IsEnglish reports whether word scores above the detector's threshold.
+func (d *EnglishDetector) IsEnglish(word string) bool {
+	return d.Score(word) > d.threshold
+}
+
+// letterModel is a letter bigram model with add-one smoothing over the
+// lowercase ASCII letters and a word boundary.
+type letterModel struct {
+	counts [27][27]int
+	totals [27]int
+}
+
+const letterBoundary = 26
+
+func newLetterModel() *letterModel {
+	return &letterModel{}
+}
+
+func letterIndex(ch byte) int {
+	if ch >= 'a' && ch <= 'z' {
+		return int(ch - 'a')
+	}
+	return letterBoundary
+}
+
+func (m *letterModel) train(word string) {
+	prev := letterBoundary
+	for i := 0; i <= len(word); i++ {
+		next := letterBoundary
+		if i < len(word) {
+			next = letterIndex(word[i])
+		}
+		m.counts[prev][next]++
+		m.totals[prev]++
+		prev = next
+	}
+}
+
+func (m *letterModel) logProb(word string) float64 {
+	logProb := 0.0
+	prev := letterBoundary
+	for i := 0; i <= len(word); i++ {
+		next := letterBoundary
+		if i < len(word) {
+			next = letterIndex(word[i])
+		}
+		logProb += math.Log(float64(m.counts[prev][next]+1) / float64(m.totals[prev]+27))
+		prev = next
+	}
+	return logProb
+}
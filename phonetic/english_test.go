@@ -0,0 +1,119 @@
+package phonetic
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Share of the labeled words the detector must classify right at
+// DefaultEnglishThreshold. Bengali left unconverted is the worse mistake,
+// so Bengali words must be right more often than English ones.
+var minEnglishAccuracy = map[string]float64{"en": 0.70, "bn": 0.90}
+
+func TestEnglishDetectorAccuracy(t *testing.T) {
+	f, err := os.Open("testdata/english_corpus.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	detector := NewEnglishDetector(DefaultEnglishThreshold)
+	total := map[string]int{}
+	correct := map[string]int{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		label, word, ok := strings.Cut(line, "\t")
+		if !ok || (label != "en" && label != "bn") {
+			t.Fatalf("bad fixture line %q", line)
+		}
+		total[label]++
+		if detector.IsEnglish(word) == (label == "en") {
+			correct[label]++
+		} else {
+			t.Logf("%s word %q scored %.2f", label, word, detector.Score(word))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, label := range []string{"en", "bn"} {
+		accuracy := float64(correct[label]) / float64(total[label])
+		t.Logf("%s: %d of %d right (%.0f%%)", label, correct[label], total[label], 100*accuracy)
+		if accuracy < minEnglishAccuracy[label] {
+			t.Errorf("%s accuracy %.2f is below %.2f", label, accuracy, minEnglishAccuracy[label])
+		}
+	}
+}
+
+func TestEnglishDetectorRules(t *testing.T) {
+	detector := NewEnglishDetector(DefaultEnglishThreshold)
+	tests := []struct {
+		word    string
+		english bool
+	}{
+		{"hello", true}, // in the lexicon
+		{"ami", false},  // in the Bengali list
+		{"kaTa", false}, // capital inside a word
+		{"abc1", false}, // digit
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := detector.IsEnglish(tt.word); got != tt.english {
+			t.Errorf("IsEnglish(%q) = %v, want %v", tt.word, got, tt.english)
+		}
+	}
+
+	detector.AddException("Hello")
+	if detector.IsEnglish("hello") {
+		t.Error("an exception still counts as English")
+	}
+}
+
+func TestEnglishDetectorShortBengaliWords(t *testing.T) {
+	// Short Bengali words as typed that are, or look like, English words
+	detector := NewEnglishDetector(DefaultEnglishThreshold)
+	for _, word := range []string{
+		"a", "am", "at", "man", "more", "bag", "bad", "name",
+		"hat", "pet", "bat", "mat", "dat", "ghat", "sat", "pat",
+		"mal", "hal", "gal", "lal", "gol", "tel", "tok", "pith", "dak",
+		"age", "tin", "mane", "din", "rat", "bon", "jol",
+	} {
+		if detector.IsEnglish(word) {
+			t.Errorf("IsEnglish(%q) = true, scored %.2f", word, detector.Score(word))
+		}
+	}
+}
+
+func TestConvertKeepsEnglish(t *testing.T) {
+	engine := New(WithEnglishDetector(NewEnglishDetector(DefaultEnglishThreshold)))
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"meeting ami ok", "meeting আমি ok"},
+		{"ami meeting, tumi?", "আমি meeting, তুমি?"},
+		{"(hello)", "(hello)"},
+		{"kaTa email", "কাটা email"},
+		{"email2", "এমাইল২"}, // a digit makes it no English word
+	}
+	for _, tt := range tests {
+		if got := engine.Convert(tt.input); got != tt.want {
+			t.Errorf("Convert(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+		// Typed in a session, the same text comes out
+		if got := typeText(engine.NewSession(), tt.input+" "); got != tt.want+" " {
+			t.Errorf("typing %q = %q, want %q", tt.input, got, tt.want+" ")
+		}
+	}
+
+	if got, want := New().Convert("meeting ami ok"), "মেএতিং আমি অক"; got != want {
+		t.Errorf("Convert without a detector = %q, want %q", got, want)
+	}
+}
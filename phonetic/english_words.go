@@ -0,0 +1,14 @@
+package phonetic
+
+// Common English words, used as the English lexicon and to train the English
+// letter model. Words that are also common Bengali as typed, such as a (আ),
+// man (মান) or bag (বাঘ), are left out.
+const englishWords = `
+about above account actually add address after again against age ago agree air all almost alone along already also although always among amount an and android animal another answer any anyone anything app apple application april are area around art article as ask attack attention august available away baby back ball bank base be beautiful because become bed been before begin behind believe below best better between big bill birthday black blog blue board body book both box boy break bring brother budget build business busy but buy by call came camera can cancel car card care carry case cat cause center certain chair chance change charge chat check child choose church city class clean clear client close club code coffee cold college color come comment common company computer confirm contact content control cool copy corner cost could country course cover create cricket cup customer cut dad daily dark data date daughter day dead deal dear death december decide deep delete design detail develop did die different dinner direct discuss do doctor does dog doing done door down download draft draw dream dress drink drive drop during each early easy eat edit education effect either else email end engineer enjoy enough enter error even evening event ever every everyone everything exam example excuse exercise expect experience explain eye face facebook fact fail fall family far fast father favorite february feel few field file fill film final find fine finish fire first fish five floor follow food for force forget form forward four free friday friend from front full fun future game garden gave general get girl give glad go goal god going gone good google got great green group grow guess guy had hair half hand happen happy hard has have he head health hear heart hello help her here high him his history hold holiday home hope hospital hot hotel hour house how however hundred husband idea if important in include inform information inside instead interest internet interview into invite is issue it item its january job join july june just keep key kid kind kitchen know laptop large last late later laugh law learn least leave left less let letter level life light like line link list listen little live load local long look lose lost lot love low lunch machine made mail main make manager many march market match matter may maybe me mean meeting member message met middle might mind minute miss mobile moment monday money month morning most mother move movie much music must my need network never new news next nice night nine no nobody none nor normal not note nothing notice november now number object october of off offer office often oh ok okay old on once one online only open option or order other our out over own page paper parent park part party pass password past pay payment people per perfect person phone photo pick picture place plan play please plus point police post power present president pretty price print private problem process product program project public put question quick quite rain ran rather reach read ready real really reason receive record red remember reply report request rest result return right road room round rule run safe said sale same saturday save saw say school screen search season second see seem seen sell send sent september service set seven several share she shop short should show side sign simple since sister sit six size sleep slow small smile so some someone something sometimes son song soon sorry sort sound speak special spend sport staff stand start state stay still stop store story street strong student study stuff subject success such summer sunday support sure system table take talk team tell ten test than thank thanks that the their them then there these they thing think third this those though thought three through thursday ticket time today together told tomorrow tonight too took top total touch town track train travel tree trip true try tuesday turn twitter two type under understand until up update upload us use user usually very video view visit voice wait walk wall want war was watch water way we wear website wednesday week weekend welcome well went were what when where whether which while white who whole why wife will win window winter wish with within without woman wonder word work world would write wrong yeah year yes yesterday yet you young your youtube zoom
+`
+
+// Common Bengali words as typed phonetically, used to train the Bengali
+// letter model. Words listed here are never treated as English.
+const bengaliWords = `
+ami amar amake amra amader tumi tomar tomake tomra tomader apni apnar apnake apnara se tar take tara tader o or ora ei eta egulo oi ota ki ki kothay keno kemon kobe kokhon ke kar kake koto kon kono kichu na ha hya ache achi acho achen chilo chilam chile thaki thake thako hoy hobe hoyeche hoyechilo holo hok kori kore koro koren korchi korche korbo korbe korlam korecho korechi korte korun jai jay jao jan jabo jabe gelam geche gechilo jete dekhi dekhe dekho dekhun dekhlam dekhte boli bole bolo bolun bollam bolechi bolte shuni shune shono shunun khai khay khao khabo khabar khete pori pore poro porbo porlam porte likhi likhe likho likhbo likhte ditam dilam dao dibo diye dite nao nibo niye nite pai pay pabo pelam pete asi ase aso asun asbo aslam eshe aste thik bhalo bhalobasa bhalobasi kharap sundor boro choto notun purono onek aro sob shob kichu ekhon tokhon ajke ajk kal kalke porshu shokal bikal sondhya rat din dupur bhai bon ma baba chele meye manush bondhu ghor bari rasta shohor gram desh bangla bangladesh kolkata dhaka bhasha kotha gan golpo boi pani bhat mach dal cha kaj taka poisa somoy jibon mon prem dukkho shukh anondo bhoy rag hashi kanna ekta dui tin char panch choy saat aat noy dosh prothom ditiyo shesh shuru jonno theke porjonto moto songe kache upore niche bhitore baire age pore jodi tahole kintu ebong ar othoba tai tobe karon hoyto obosshoi dhonnobad shuvo nomoskar salam aschi jacchi korchilam bujhlam bujhi bujhte jani jane jano janina parbo pari pare paro parina chai chay chao dorkar lagbe lage laglo mone mane hridoy kobita shilpo songit itihash biggan bishwobiddaloy bidyaloy kolej shikkhok chatro chatri porikkha folafol khela ranna khawa ghum swapno akash megh brishti rod batash nodi shagor pahar gach phul pakhi a am at man more bag bad name hat pet bat mat dat ghat sat pat mal hal gal lal gol tel tok pith dak
+`
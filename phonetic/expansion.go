@@ -0,0 +1,67 @@
+package phonetic
+
+import (
+	"time"
+	"unicode"
+)
+
+// Expansions replace a whole token, such as :) or :taka:, before phonetic
+// conversion. A token runs from one whitespace to the next, so : and .
+// inside a word keep their meaning as ঃ and ।. Macros expand the same way.
+
+// expansion returns what token expands to.
+func (e *Engine) expansion(token string) (string, bool) {
+	if output, ok := e.keymap.Expansions[token]; ok {
+		return output, true
+	}
+	if output, ok := e.macros.Expand(token, time.Now()); ok {
+		return output, true
+	}
+	return dateMacros.Expand(token, time.Now())
+}
+
+// isExpansionPrefix reports whether text can still grow into a token that
+// expands.
+func (e *Engine) isExpansionPrefix(text string) bool {
+	return e.expansionPrefixes[text]
+}
+
+// isWord reports whether text consists of input characters only, as
+// opposed to an unfinished expansion token like ":(" or "<".
+func (e *Engine) isWord(text string) bool {
+	for _, ch := range text {
+		if !e.isInputChar(ch) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchExpansion expands the token starting at chars[i] if it is one. It
+// returns the expansion and the token's length, or a length of zero.
+func (e *Engine) matchExpansion(chars []rune, i int) (string, int) {
+	if i > 0 && !unicode.IsSpace(chars[i-1]) {
+		return "", 0
+	}
+	end := i
+	for end < len(chars) && !unicode.IsSpace(chars[end]) {
+		end++
+	}
+	if output, ok := e.expansion(string(chars[i:end])); ok {
+		return output, end - i
+	}
+	return "", 0
+}
+
+func expansionPrefixes(tables ...map[string]string) map[string]bool {
+	prefixes := make(map[string]bool)
+	for _, table := range tables {
+		for token := range table {
+			runes := []rune(token)
+			for n := 1; n <= len(runes); n++ {
+				prefixes[string(runes[:n])] = true
+			}
+		}
+	}
+	return prefixes
+}
@@ -1,18 +1,62 @@
-package main
+package phonetic
+
+import "sort"
 
 type BengaliChar struct {
-	Bengali string
-	IsVowel bool
+	Bengali string `json:"bengali"`
+	IsVowel bool   `json:"vowel,omitempty"`
 }
 
 type KeyMap struct {
-	Patterns        map[string]BengaliChar
-	VowelDiacritics map[string]string
+	Patterns        map[string]BengaliChar `json:"patterns"`
+	VowelDiacritics map[string]string      `json:"vowel_diacritics"`
+	Punctuation     map[string]string      `json:"punctuation"` // commit triggers typed as something else
+	Expansions      map[string]string      `json:"expansions"`  // whole tokens replaced before conversion
+}
+
+// Names of the built-in keymaps. DefaultKeyMapName is used when none is
+// selected.
+const (
+	DefaultKeyMapName = "phonetic"
+	AvroKeyMapName    = "avro"
+)
+
+// Keymaps that can be selected by name, e.g. per request in the serve API,
+// with the title and description of their scheme, in cycling order.
+var keymaps = []struct {
+	name, title, description string
+	build                    func() *KeyMap
+}{
+	{DefaultKeyMapName, "Phonetic", "The built-in phonetic scheme", NewKeyMap},
+	{AvroKeyMapName, "Avro Phonetic", "Avro-compatible: sh is শ, ch is চ, x is ক্স, Z is য-ফলা and ^ is চন্দ্রবিন্দু", NewAvroKeyMap},
+}
+
+// LookupKeyMap builds the keymap registered under name.
+func LookupKeyMap(name string) (*KeyMap, bool) {
+	for _, k := range keymaps {
+		if k.name == name {
+			return k.build(), true
+		}
+	}
+	return nil, false
+}
+
+// KeyMapNames lists the registered keymap names in sorted order.
+func KeyMapNames() []string {
+	names := make([]string, 0, len(keymaps))
+	for _, k := range keymaps {
+		names = append(names, k.name)
+	}
+	sort.Strings(names)
+	return names
 }
 
+// NewKeyMap returns the default phonetic keymap.
 func NewKeyMap() *KeyMap {
 	patterns := make(map[string]BengaliChar)
 	vowelDiacritics := make(map[string]string)
+	punctuation := make(map[string]string)
+	expansions := make(map[string]string)
 
 	// Independent vowels (স্বরবর্ণ)
 	patterns["o"] = BengaliChar{Bengali: "অ", IsVowel: true}
@@ -191,8 +235,61 @@ func NewKeyMap() *KeyMap {
 	patterns["$"] = BengaliChar{Bengali: "৳", IsVowel: false}
 	patterns["aya"] = BengaliChar{Bengali: "অ্যা", IsVowel: false}
 
+	// Joiners controlling how consonant clusters render
+	patterns["^"] = BengaliChar{Bengali: "\u09CD\u200C", IsVowel: false} // hasanta + ZWNJ: visible hasanta, k^Sh = ক্‌ষ
+	patterns["~"] = BengaliChar{Bengali: "\u200D\u09CD", IsVowel: false} // ZWJ + hasanta: full form before a phola, r~zab = র‍্যাব
+
+	// Punctuation typed after converting the word before it
+	punctuation["|"] = "।"
+
+	// Emoticons and symbols, expanded when typed as a whole token
+	expansions[":)"] = "\U0001F642"
+	expansions[":("] = "\U0001F641"
+	expansions[":D"] = "\U0001F600"
+	expansions[";)"] = "\U0001F609"
+	expansions["<3"] = "\u2764\uFE0F"
+	expansions[":heart:"] = "\u2764\uFE0F"
+	expansions[":taka:"] = "৳"
+	expansions[":isshar:"] = "৺"
+	expansions[":ganda:"] = "৻"
+	expansions[":sixteenth:"] = "৹"
+	expansions[":lsquo:"] = "‘"
+	expansions[":rsquo:"] = "’"
+	expansions[":ldquo:"] = "“"
+	expansions[":rdquo:"] = "”"
+	expansions["--"] = "—"
+
 	return &KeyMap{
 		Patterns:        patterns,
 		VowelDiacritics: vowelDiacritics,
+		Punctuation:     punctuation,
+		Expansions:      expansions,
 	}
 }
+
+// NewAvroKeyMap returns the built-in keymap changed to type like Avro
+// Phonetic, so people used to Avro can keep their habits.
+func NewAvroKeyMap() *KeyMap {
+	keymap := NewKeyMap()
+	patterns := keymap.Patterns
+
+	patterns["sh"] = BengaliChar{Bengali: "শ", IsVowel: false}
+	patterns["S"] = BengaliChar{Bengali: "শ", IsVowel: false}
+	patterns["Sh"] = BengaliChar{Bengali: "ষ", IsVowel: false}
+	patterns["shr"] = BengaliChar{Bengali: "শ্র", IsVowel: false}
+	patterns["ch"] = BengaliChar{Bengali: "চ", IsVowel: false}
+	patterns["chh"] = BengaliChar{Bengali: "ছ", IsVowel: false}
+	patterns["chr"] = BengaliChar{Bengali: "চ্র", IsVowel: false}
+	patterns["x"] = BengaliChar{Bengali: "ক্স", IsVowel: false}
+	patterns["q"] = BengaliChar{Bengali: "ক", IsVowel: false}
+	patterns["J"] = BengaliChar{Bengali: "জ", IsVowel: false}
+	patterns["Z"] = BengaliChar{Bengali: "্য", IsVowel: false} // য-ফলা
+	patterns["^"] = BengaliChar{Bengali: "ঁ", IsVowel: false}
+	patterns["+"] = BengaliChar{Bengali: "\u09CD\u200C", IsVowel: false} // visible hasanta, which ^ types elsewhere
+	patterns["OI"] = BengaliChar{Bengali: "ঐ", IsVowel: true}
+	patterns["OU"] = BengaliChar{Bengali: "ঔ", IsVowel: true}
+	keymap.VowelDiacritics["OI"] = "ৈ"
+	keymap.VowelDiacritics["OU"] = "ৌ"
+
+	return keymap
+}
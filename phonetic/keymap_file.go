@@ -0,0 +1,110 @@
+package phonetic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// keymapFile is the JSON form of a keymap:
+//
+//	{
+//	  "base": "phonetic",
+//	  "patterns": {"kh": "খ", "o": {"bengali": "অ", "vowel": true}, "q": ""},
+//	  "vowel_diacritics": {"o": ""},
+//	  "punctuation": {"|": "।"},
+//	  "expansions": {":)": "\ud83d\ude42", "--": ""}
+//	}
+//
+// A pattern maps to a string, or to an object to mark it as a vowel. With a
+// base the file changes a registered keymap, and an empty string removes
+// a pattern or expansion of the base. Expansions are whole tokens without
+// spaces. Joiners are written as escapes, e.g.
+// "\u200c" for ZWNJ and "\u200d" for ZWJ.
+type keymapFile struct {
+	Base            string                 `json:"base,omitempty"`
+	Patterns        map[string]BengaliChar `json:"patterns"`
+	VowelDiacritics map[string]string      `json:"vowel_diacritics"`
+	Punctuation     map[string]string      `json:"punctuation"`
+	Expansions      map[string]string      `json:"expansions"`
+}
+
+// UnmarshalJSON accepts a plain string for a consonant or other pattern.
+func (c *BengaliChar) UnmarshalJSON(data []byte) error {
+	var bengali string
+	if err := json.Unmarshal(data, &bengali); err == nil {
+		*c = BengaliChar{Bengali: bengali}
+		return nil
+	}
+
+	type plain BengaliChar
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// LoadKeyMapFile reads a keymap from a JSON file.
+func LoadKeyMapFile(path string) (*KeyMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keymap, err := ParseKeyMap(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return keymap, nil
+}
+
+// ParseKeyMap decodes a keymap in the JSON form described at keymapFile.
+func ParseKeyMap(data []byte) (*KeyMap, error) {
+	var file keymapFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	keymap := &KeyMap{
+		Patterns:        make(map[string]BengaliChar),
+		VowelDiacritics: make(map[string]string),
+		Punctuation:     make(map[string]string),
+		Expansions:      make(map[string]string),
+	}
+	if file.Base != "" {
+		base, ok := LookupKeyMap(file.Base)
+		if !ok {
+			return nil, fmt.Errorf("unknown base keymap %q", file.Base)
+		}
+		keymap = base
+	}
+
+	for pattern, bengaliChar := range file.Patterns {
+		if pattern == "" {
+			return nil, fmt.Errorf("empty pattern")
+		}
+		if bengaliChar.Bengali == "" {
+			delete(keymap.Patterns, pattern)
+			continue
+		}
+		keymap.Patterns[pattern] = bengaliChar
+	}
+	for pattern, diacritic := range file.VowelDiacritics {
+		keymap.VowelDiacritics[pattern] = diacritic
+	}
+	for char, bengali := range file.Punctuation {
+		if len([]rune(char)) != 1 {
+			return nil, fmt.Errorf("punctuation %q is not a single character", char)
+		}
+		keymap.Punctuation[char] = bengali
+	}
+	for token, output := range file.Expansions {
+		if token == "" || strings.IndexFunc(token, unicode.IsSpace) >= 0 {
+			return nil, fmt.Errorf("expansion %q is not a single token", token)
+		}
+		if output == "" {
+			delete(keymap.Expansions, token)
+			continue
+		}
+		keymap.Expansions[token] = output
+	}
+	return keymap, nil
+}
@@ -0,0 +1,87 @@
+package phonetic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJoiners(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []rune
+	}{
+		{"k^Sh", []rune{0x0995, 0x09CD, 0x200C, 0x09B7}},
+		{"r~zab", []rune{0x09B0, 0x200D, 0x09CD, 0x09AF, 0x09BE, 0x09AC}},
+	}
+	engine := New()
+	for _, tt := range tests {
+		if got := engine.Convert(tt.input); got != string(tt.want) {
+			t.Errorf("Convert(%q) = %U, want %U", tt.input, []rune(got), tt.want)
+		}
+	}
+
+	// Avro types চন্দ্রবিন্দু with ^ and the visible hasanta with +
+	avro := New(WithKeyMap(NewAvroKeyMap()))
+	for input, want := range map[string][]rune{
+		"k+Sh":  {0x0995, 0x09CD, 0x200C, 0x09B7},
+		"cha^d": {0x099A, 0x09BE, 0x0981, 0x09A6},
+	} {
+		if got := avro.Convert(input); got != string(want) {
+			t.Errorf("avro Convert(%q) = %U, want %U", input, []rune(got), want)
+		}
+	}
+	s := avro.NewSession()
+	if got, want := typeText(s, "k+Sh "), "\u0995\u09CD\u200C\u09B7 "; got != want {
+		t.Errorf("avro typing k+Sh = %U, want %U", []rune(got), []rune(want))
+	}
+}
+
+func TestParseKeyMap(t *testing.T) {
+	keymap, err := ParseKeyMap([]byte(`{
+		"base": "phonetic",
+		"patterns": {"q": "ক", "x": {"bengali": "অ", "vowel": true}, "kh": ""},
+		"punctuation": {"|": "।"},
+		"expansions": {":ok:": "👌", "--": ""}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := keymap.Patterns["q"]; got != (BengaliChar{Bengali: "ক"}) {
+		t.Errorf("pattern q = %+v, want ক", got)
+	}
+	if got := keymap.Patterns["x"]; got != (BengaliChar{Bengali: "অ", IsVowel: true}) {
+		t.Errorf("pattern x = %+v, want vowel অ", got)
+	}
+	if _, ok := keymap.Patterns["kh"]; ok {
+		t.Error("pattern kh was not removed")
+	}
+	if _, ok := keymap.Patterns["gh"]; !ok {
+		t.Error("pattern gh of the base is missing")
+	}
+	if _, ok := keymap.Expansions["--"]; ok {
+		t.Error("expansion -- was not removed")
+	}
+	if got := keymap.Expansions[":ok:"]; got != "👌" {
+		t.Errorf("expansion :ok: = %q, want 👌", got)
+	}
+}
+
+func TestParseKeyMapErrors(t *testing.T) {
+	tests := []struct {
+		json string
+		want string
+	}{
+		{`{"punctuation": {"ab": "।"}}`, "not a single character"},
+		{`{"base": "nope"}`, "unknown base keymap"},
+		{`{"patterns": {"": "ক"}}`, "empty pattern"},
+		{`{"expansions": {"a b": "x"}}`, "not a single token"},
+		{`{"patterns": [}`, "invalid character"},
+	}
+	for _, tt := range tests {
+		_, err := ParseKeyMap([]byte(tt.json))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseKeyMap(%s) error = %v, want one containing %q", tt.json, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package phonetic
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Macros are user-defined abbreviations that expand when typed as a whole
+// word, such as "bdgov" for বাংলাদেশ সরকার. An expansion may contain the
+// date placeholders of FormatDate, like {date} or {bangla_date}, which are
+// filled in when it is typed. :date: and :bdate: are always available.
+type Macros map[string]string
+
+// WithMacros makes the engine expand macros.
+func WithMacros(macros Macros) Option {
+	return func(e *Engine) {
+		e.macros = macros
+	}
+}
+
+// Built-in macros inserting today's date.
+var dateMacros = Macros{
+	":date:":  "{date_long}",
+	":bdate:": "{bangla_date}",
+}
+
+// ValidateMacro checks that name can be typed as a single word and that
+// text uses known placeholders only.
+func ValidateMacro(name, text string) error {
+	if name == "" || strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("macro name %q is not a single word", name)
+	}
+	if text == "" {
+		return fmt.Errorf("macro %q is empty", name)
+	}
+	if err := ValidateDatePattern(text); err != nil {
+		return fmt.Errorf("macro %q: %w", name, err)
+	}
+	return nil
+}
+
+// CheckMacroName reports a macro name that clashes with keymap: a macro
+// named like an expansion never expands, since the expansion comes first,
+// and one named like a pattern, such as "k", replaces that letter whenever
+// it is typed on its own.
+func CheckMacroName(name string, keymap *KeyMap) error {
+	if _, ok := keymap.Expansions[name]; ok {
+		return fmt.Errorf("macro name %q is a keymap expansion", name)
+	}
+	if _, ok := keymap.Patterns[name]; ok {
+		return fmt.Errorf("macro name %q is a keymap pattern", name)
+	}
+	return nil
+}
+
+// Expand returns the expansion of the macro called name at time now.
+func (m Macros) Expand(name string, now time.Time) (string, bool) {
+	text, ok := m[name]
+	if !ok {
+		return "", false
+	}
+	return FormatDate(now, text), true
+}
@@ -0,0 +1,67 @@
+package phonetic
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateMacro(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string // part of the error, "" for none
+	}{
+		{"bdgov", "বাংলাদেশ সরকার", ""},
+		{"aj", "আজ {day}, {date}", ""},
+		{"", "বাংলাদেশ", "is not a single word"},
+		{"bd gov", "বাংলাদেশ সরকার", "is not a single word"},
+		{"bd\tgov", "বাংলাদেশ সরকার", "is not a single word"},
+		{"bdgov", "", "is empty"},
+		{"aj", "আজ {today}", "unknown placeholder {today}"},
+	}
+	for _, tt := range tests {
+		err := ValidateMacro(tt.name, tt.text)
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("ValidateMacro(%q, %q) = %v, want %q", tt.name, tt.text, err, tt.want)
+		}
+	}
+}
+
+func TestCheckMacroName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"bdgov", ""},
+		{"ami", ""},
+		{"k", "is a keymap pattern"},
+		{"kh", "is a keymap pattern"},
+		{":)", "is a keymap expansion"},
+		{":taka:", "is a keymap expansion"},
+		{":date:", ""}, // built-in macros can be redefined
+	}
+	keymap := NewKeyMap()
+	for _, tt := range tests {
+		err := CheckMacroName(tt.name, keymap)
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("CheckMacroName(%q) = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestMacroExpansion(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 5, 0, 0, time.UTC)
+	macros := Macros{"bdgov": "বাংলাদেশ সরকার", "aj": "আজ {date}"}
+	if got, ok := macros.Expand("aj", now); !ok || got != "আজ ১৮/১০/২০২৬" {
+		t.Errorf(`Expand("aj") = %q, %v, want আজ ১৮/১০/২০২৬`, got, ok)
+	}
+	if _, ok := macros.Expand("bd", now); ok {
+		t.Error(`Expand("bd") found a macro`)
+	}
+
+	engine := New(WithMacros(macros))
+	if got, want := typeText(engine.NewSession(), "ami bdgov, bdgovt "), "আমি বাংলাদেশ সরকার, ব্দগভত "; got != want {
+		t.Errorf("typing macros gave %q, want %q", got, want)
+	}
+}
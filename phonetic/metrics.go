@@ -0,0 +1,157 @@
+package phonetic
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Metrics counts what sessions do: words converted, keymap patterns used,
+// conversions undone and how long key handling takes. No typed text is
+// kept. A nil *Metrics records nothing, and all methods are safe for
+// concurrent use.
+type Metrics struct {
+	mutex          sync.Mutex
+	convertedWords int64
+	undos          int64
+	patternHits    map[string]int64
+	latencyTotal   time.Duration
+	latencyCount   int64
+}
+
+// MetricsSnapshot is a copy of the counters, in a form that can be saved.
+type MetricsSnapshot struct {
+	ConvertedWords int64            `json:"converted_words"`
+	Undos          int64            `json:"undos"`
+	PatternHits    map[string]int64 `json:"pattern_hits"`
+	LatencyTotal   time.Duration    `json:"latency_total_ns"`
+	LatencyCount   int64            `json:"latency_count"`
+}
+
+// PatternCount is how often a keymap pattern was used.
+type PatternCount struct {
+	Pattern string
+	Count   int64
+}
+
+// WithMetrics makes sessions of the engine record into metrics.
+func WithMetrics(metrics *Metrics) Option {
+	return func(e *Engine) {
+		e.metrics = metrics
+	}
+}
+
+// NewMetrics returns metrics with nothing counted yet.
+func NewMetrics() *Metrics {
+	return &Metrics{patternHits: make(map[string]int64)}
+}
+
+// Restore continues counting from a saved snapshot.
+func (m *Metrics) Restore(snapshot MetricsSnapshot) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.convertedWords = snapshot.ConvertedWords
+	m.undos = snapshot.Undos
+	m.patternHits = make(map[string]int64)
+	for pattern, count := range snapshot.PatternHits {
+		m.patternHits[pattern] = count
+	}
+	m.latencyTotal = snapshot.LatencyTotal
+	m.latencyCount = snapshot.LatencyCount
+}
+
+// Snapshot returns a copy of the counts so far.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	snapshot := MetricsSnapshot{
+		ConvertedWords: m.convertedWords,
+		Undos:          m.undos,
+		PatternHits:    make(map[string]int64),
+		LatencyTotal:   m.latencyTotal,
+		LatencyCount:   m.latencyCount,
+	}
+	for pattern, count := range m.patternHits {
+		snapshot.PatternHits[pattern] = count
+	}
+	return snapshot
+}
+
+// ObserveLatency records the time taken to handle one key.
+func (m *Metrics) ObserveLatency(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.latencyTotal += d
+	m.latencyCount++
+}
+
+func (m *Metrics) recordConversion(matches []PatternMatch) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.convertedWords++
+	for _, match := range matches {
+		m.patternHits[metricsPattern(match)]++
+	}
+}
+
+// metricsPattern is the key a match is counted under. Numbers and
+// expansions are counted together, since what was typed for them, such as
+// a phone number, must not be stored.
+func metricsPattern(match PatternMatch) string {
+	switch match.Kind {
+	case MatchNumber:
+		return "<number>"
+	case MatchExpansion:
+		return "<expansion>"
+	}
+	return match.Pattern
+}
+
+func (m *Metrics) recordUndo() {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.undos++
+}
+
+// UndoRate is the share of converted words erased right after conversion.
+func (s MetricsSnapshot) UndoRate() float64 {
+	if s.ConvertedWords == 0 {
+		return 0
+	}
+	return float64(s.Undos) / float64(s.ConvertedWords)
+}
+
+// AverageLatency is the mean time a key took to process.
+func (s MetricsSnapshot) AverageLatency() time.Duration {
+	if s.LatencyCount == 0 {
+		return 0
+	}
+	return s.LatencyTotal / time.Duration(s.LatencyCount)
+}
+
+// TopPatterns returns the n most used patterns, most used first.
+func (s MetricsSnapshot) TopPatterns(n int) []PatternCount {
+	counts := make([]PatternCount, 0, len(s.PatternHits))
+	for pattern, count := range s.PatternHits {
+		counts = append(counts, PatternCount{pattern, count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Pattern < counts[j].Pattern
+	})
+	if n > 0 && len(counts) > n {
+		counts = counts[:n]
+	}
+	return counts
+}
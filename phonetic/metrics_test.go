@@ -0,0 +1,25 @@
+package phonetic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMetricsKeepNoTypedNumbers(t *testing.T) {
+	metrics := NewMetrics()
+	engine := New(WithMetrics(metrics))
+	typeText(engine.NewSession(), "01712345678 ami 1,234.50 42 ")
+
+	snapshot := metrics.Snapshot()
+	for pattern := range snapshot.PatternHits {
+		if strings.ContainsAny(pattern, "0123456789") {
+			t.Errorf("pattern hits keep typed digits: %q", pattern)
+		}
+	}
+	if snapshot.PatternHits["<number>"] == 0 {
+		t.Error("numbers were not counted")
+	}
+	if got := snapshot.PatternHits["m"]; got != 1 {
+		t.Errorf("hits of pattern m = %d, want 1", got)
+	}
+}
@@ -0,0 +1,161 @@
+package phonetic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NormForm is the Unicode normalization form of the engine's output. Only
+// the Bengali block is normalized; other text is left as it is.
+type NormForm int
+
+const (
+	// NFC composes ো and ৌ. ড়, ঢ় and য় are composition exclusions, so
+	// they stay as consonant + nukta.
+	NFC NormForm = iota
+	// NFD decomposes every Bengali character with a canonical decomposition.
+	NFD
+	// NormNone leaves the output as the keymap produces it.
+	NormNone
+)
+
+// WithNormalization sets the normalization form of converted text.
+func WithNormalization(form NormForm) Option {
+	return func(e *Engine) {
+		e.normForm = form
+	}
+}
+
+const (
+	nukta   = '\u09BC'
+	hasanta = '\u09CD'
+	zwj     = '\u200D'
+)
+
+// Canonical decompositions in the Bengali block.
+var bengaliDecompositions = map[rune][]rune{
+	'\u09CB': {'\u09C7', '\u09BE'}, // ো = ে + া
+	'\u09CC': {'\u09C7', '\u09D7'}, // ৌ = ে + ৗ
+	'\u09DC': {'\u09A1', nukta},    // ড়
+	'\u09DD': {'\u09A2', nukta},    // ঢ়
+	'\u09DF': {'\u09AF', nukta},    // য়
+}
+
+// Pairs that compose in NFC; ড়, ঢ় and য় are excluded from composition.
+var bengaliCompositions = map[[2]rune]rune{
+	{'\u09C7', '\u09BE'}: '\u09CB',
+	{'\u09C7', '\u09D7'}: '\u09CC',
+}
+
+// Canonical combining classes of the Bengali marks that have one.
+var bengaliCombiningClass = map[rune]int{
+	nukta:   7,
+	hasanta: 9,
+}
+
+// Normalize returns text in the given normalization form.
+func Normalize(text string, form NormForm) string {
+	if form == NormNone {
+		return text
+	}
+
+	// Decompose
+	runes := make([]rune, 0, len(text))
+	for _, ch := range text {
+		if decomposed, ok := bengaliDecompositions[ch]; ok {
+			runes = append(runes, decomposed...)
+		} else {
+			runes = append(runes, ch)
+		}
+	}
+
+	// Put runs of combining marks in canonical order
+	for i := 0; i < len(runes); {
+		if bengaliCombiningClass[runes[i]] == 0 {
+			i++
+			continue
+		}
+		end := i
+		for end < len(runes) && bengaliCombiningClass[runes[end]] != 0 {
+			end++
+		}
+		marks := runes[i:end]
+		sort.SliceStable(marks, func(a, b int) bool {
+			return bengaliCombiningClass[marks[a]] < bengaliCombiningClass[marks[b]]
+		})
+		i = end
+	}
+
+	if form == NFD {
+		return string(runes)
+	}
+
+	// Compose
+	composed := runes[:0]
+	for _, ch := range runes {
+		if n := len(composed); n > 0 {
+			if c, ok := bengaliCompositions[[2]rune{composed[n-1], ch}]; ok {
+				composed[n-1] = c
+				continue
+			}
+		}
+		composed = append(composed, ch)
+	}
+	return string(composed)
+}
+
+// Issue is a sequence in Bengali text that does not form a valid syllable.
+type Issue struct {
+	Pos     int // index of the offending rune
+	Rune    rune
+	Problem string
+}
+
+// String writes the issue as position, code point and problem.
+func (i Issue) String() string {
+	return fmt.Sprintf("%d: %U %s", i.Pos, i.Rune, i.Problem)
+}
+
+// Validate reports marks that have no letter to attach to: a kar, hasanta
+// or nukta without a base, doubled hasanta and stacked vowel signs.
+func Validate(text string) []Issue {
+	var issues []Issue
+	runes := []rune(Normalize(text, NFD))
+
+	for i, ch := range runes {
+		var prev rune
+		if i > 0 {
+			prev = runes[i-1]
+		}
+
+		switch {
+		case ch == hasanta && prev == hasanta:
+			issues = append(issues, Issue{i, ch, "doubled hasanta"})
+		case ch == hasanta && prev == zwj && i > 1 && isBengaliConsonant(runes[i-2]):
+			// র‍্য: a joiner between the consonant and its hasanta
+		case ch == hasanta && !isBengaliConsonant(prev) && prev != nukta:
+			issues = append(issues, Issue{i, ch, "hasanta without a consonant"})
+		case ch == nukta && !isBengaliConsonant(prev):
+			issues = append(issues, Issue{i, ch, "nukta without a consonant"})
+		case isVowelSign(ch) && isVowelSign(prev) && bengaliCompositions[[2]rune{prev, ch}] == 0:
+			issues = append(issues, Issue{i, ch, "vowel sign after another vowel sign"})
+		case isVowelSign(ch) && !isVowelSign(prev) && !isBengaliConsonant(prev) && prev != nukta:
+			issues = append(issues, Issue{i, ch, "vowel sign without a consonant"})
+		}
+	}
+	return issues
+}
+
+// isVowelSign reports whether ch is a dependent vowel sign (kar), including
+// the length mark of ৌ.
+func isVowelSign(ch rune) bool {
+	return (ch >= '\u09BE' && ch <= '\u09CC') || // া to ৌ
+		ch == '\u09D7' || // ৌ length mark
+		ch == '\u09E2' || ch == '\u09E3' // ৢ and ৣ
+}
+
+// trimNukta drops a trailing nukta so the consonant it belongs to is last.
+func trimNukta(text string) string {
+	return strings.TrimSuffix(text, string(nukta))
+}
@@ -0,0 +1,116 @@
+package phonetic
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// randomBengali returns n random runes of the Bengali block, with a few
+// joiners, spaces and Latin letters mixed in.
+func randomBengali(r *rand.Rand, n int) string {
+	extra := []rune{zwj, '\u200C', ' ', 'a'}
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		if r.Intn(10) == 0 {
+			b.WriteRune(extra[r.Intn(len(extra))])
+		} else {
+			b.WriteRune(rune(0x0980 + r.Intn(0x80)))
+		}
+	}
+	return b.String()
+}
+
+func TestNormalizeProperties(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		text := randomBengali(r, 1+r.Intn(12))
+		nfc := Normalize(text, NFC)
+		nfd := Normalize(text, NFD)
+
+		if again := Normalize(nfc, NFC); again != nfc {
+			t.Fatalf("NFC not idempotent for %+q: %+q then %+q", text, nfc, again)
+		}
+		if again := Normalize(nfd, NFD); again != nfd {
+			t.Fatalf("NFD not idempotent for %+q: %+q then %+q", text, nfd, again)
+		}
+		if got := Normalize(nfd, NFC); got != nfc {
+			t.Fatalf("NFC(NFD(%+q)) = %+q, want NFC %+q", text, got, nfc)
+		}
+		if got := Normalize(nfc, NFD); got != nfd {
+			t.Fatalf("NFD(NFC(%+q)) = %+q, want NFD %+q", text, got, nfd)
+		}
+		if got := Normalize(text, NormNone); got != text {
+			t.Fatalf("NormNone changed %+q to %+q", text, got)
+		}
+	}
+}
+
+func TestConvertOutputIsNFC(t *testing.T) {
+	engine := New()
+	var letters []string
+	for pattern := range engine.keymap.Patterns {
+		letters = append(letters, pattern)
+	}
+	for pattern := range engine.keymap.VowelDiacritics {
+		letters = append(letters, pattern)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		var input strings.Builder
+		for n := 1 + r.Intn(6); n > 0; n-- {
+			input.WriteString(letters[r.Intn(len(letters))])
+		}
+		output := engine.Convert(input.String())
+		if nfc := Normalize(output, NFC); nfc != output {
+			t.Fatalf("Convert(%q) = %+q, not NFC %+q", input.String(), output, nfc)
+		}
+	}
+}
+
+func TestNormalizeForms(t *testing.T) {
+	tests := []struct {
+		text, nfc, nfd string
+	}{
+		{"\u0995\u09CB", "\u0995\u09CB", "\u0995\u09C7\u09BE"},             // কো
+		{"\u0995\u09C7\u09D7", "\u0995\u09CC", "\u0995\u09C7\u09D7"},       // কৌ
+		{"\u09DF", "\u09AF\u09BC", "\u09AF\u09BC"},                         // য় is not composed
+		{"\u0995\u09CD\u09BC", "\u0995\u09BC\u09CD", "\u0995\u09BC\u09CD"}, // nukta before hasanta
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.text, NFC); got != tt.nfc {
+			t.Errorf("NFC(%+q) = %+q, want %+q", tt.text, got, tt.nfc)
+		}
+		if got := Normalize(tt.text, NFD); got != tt.nfd {
+			t.Errorf("NFD(%+q) = %+q, want %+q", tt.text, got, tt.nfd)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		text     string
+		problems []string
+	}{
+		{"আমি বাংলায় গান গাই", nil},
+		{"কোথায়", nil},
+		{"\u09B0\u200D\u09CD\u09AF\u09BE\u09AC", nil}, // র‍্যাব
+		{"\u0995\u09CD\u200C\u09B7", nil},             // visible hasanta
+		{"িক", []string{"vowel sign without a consonant"}},
+		{"ক্্", []string{"doubled hasanta"}},
+		{"্", []string{"hasanta without a consonant"}},
+		{"়", []string{"nukta without a consonant"}},
+		{"কিু", []string{"vowel sign after another vowel sign"}},
+	}
+	for _, tt := range tests {
+		var problems []string
+		for _, issue := range Validate(tt.text) {
+			problems = append(problems, issue.Problem)
+		}
+		if !reflect.DeepEqual(problems, tt.problems) {
+			t.Errorf("Validate(%+q) = %q, want %q", tt.text, problems, tt.problems)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package phonetic
+
+import "strings"
+
+// DigitMode selects how typed digits are written.
+type DigitMode int
+
+const (
+	// DigitsBengali writes all digits as Bengali digits.
+	DigitsBengali DigitMode = iota
+	// DigitsASCII keeps all digits as typed.
+	DigitsASCII
+	// DigitsContext keeps times, phone numbers and digits inside words such
+	// as codes as typed, and writes other numbers with Bengali digits.
+	DigitsContext
+)
+
+// WithDigitMode sets how the engine writes digits.
+func WithDigitMode(mode DigitMode) Option {
+	return func(e *Engine) {
+		e.digitMode = mode
+	}
+}
+
+// WithNumberFormatting groups the digits of long numbers the Bengali way,
+// in lakhs and crores (১২,৩৪,৫৬৭).
+func WithNumberFormatting(enabled bool) Option {
+	return func(e *Engine) {
+		e.formatNumbers = enabled
+	}
+}
+
+var bengaliDigits = []rune("০১২৩৪৫৬৭৮৯")
+
+// number is a number token in typed text, such as 500, $1200, 3.14, 10:30
+// or 2nd.
+type number struct {
+	currency bool
+	integer  string
+	fraction string // digits after a decimal point
+	minutes  string // digits after the colon of a time
+	ordinal  bool   // followed by st, nd, rd or th
+}
+
+// matchNumber parses a number token starting at chars[i]. It returns the
+// token and its length, or a length of zero when there is none.
+func matchNumber(chars []rune, i int) (number, int) {
+	var num number
+	start := i
+
+	if i > 0 && isASCIIAlnum(chars[i-1]) {
+		return num, 0
+	}
+	if i < len(chars) && chars[i] == '$' {
+		num.currency = true
+		i++
+	}
+
+	digits := func() string {
+		from := i
+		for i < len(chars) && chars[i] >= '0' && chars[i] <= '9' {
+			i++
+		}
+		return string(chars[from:i])
+	}
+
+	if num.integer = digits(); num.integer == "" {
+		return num, 0
+	}
+
+	// A separator only counts when digits follow it; a trailing dot is a দাঁড়ি
+	if i+1 < len(chars) && isASCIIDigit(chars[i+1]) {
+		switch {
+		case chars[i] == '.':
+			i++
+			num.fraction = digits()
+		case chars[i] == ':' && !num.currency:
+			i++
+			num.minutes = digits()
+		}
+	}
+
+	if !num.currency && num.fraction == "" && num.minutes == "" && i+2 <= len(chars) {
+		switch strings.ToLower(string(chars[i : i+2])) {
+		case "st", "nd", "rd", "th":
+			num.ordinal = true
+			i += 2
+		}
+	}
+
+	if i < len(chars) && isASCIIAlnum(chars[i]) {
+		return number{}, 0
+	}
+	return num, i - start
+}
+
+// isPhone reports whether the number looks like a phone number: a long
+// run of digits, or one with a leading zero.
+func (n number) isPhone() bool {
+	if n.currency || n.fraction != "" || n.minutes != "" || n.ordinal {
+		return false
+	}
+	return len(n.integer) >= 10 || (len(n.integer) >= 7 && n.integer[0] == '0')
+}
+
+func (e *Engine) formatNumber(n number) string {
+	ascii := e.digitMode == DigitsASCII ||
+		(e.digitMode == DigitsContext && (n.minutes != "" || n.isPhone()))
+
+	var result strings.Builder
+	if n.currency {
+		result.WriteString("৳")
+	}
+
+	integer := n.integer
+	if e.formatNumbers && !n.isPhone() && (len(integer) > 4 || (n.currency && len(integer) > 3)) {
+		integer = groupLakhs(integer)
+	}
+	result.WriteString(integer)
+
+	if n.fraction != "" {
+		result.WriteString("." + n.fraction)
+	}
+	if n.minutes != "" {
+		result.WriteString(":" + n.minutes)
+	}
+
+	output := result.String()
+	if !ascii {
+		output = toBengaliDigits(output)
+	}
+	if n.ordinal {
+		output += ordinalSuffix(n.integer)
+	}
+	return output
+}
+
+// groupLakhs puts commas into a string of digits after the last three
+// and then after every two: 1234567 becomes 12,34,567.
+func groupLakhs(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	head, tail := digits[:len(digits)-3], digits[len(digits)-3:]
+	var groups []string
+	for len(head) > 2 {
+		groups = append([]string{head[len(head)-2:]}, groups...)
+		head = head[:len(head)-2]
+	}
+	groups = append([]string{head}, groups...)
+	return strings.Join(groups, ",") + "," + tail
+}
+
+func toBengaliDigits(s string) string {
+	return strings.Map(func(ch rune) rune {
+		if isASCIIDigit(ch) {
+			return bengaliDigits[ch-'0']
+		}
+		return ch
+	}, s)
+}
+
+// ordinalSuffix returns the Bengali ordinal ending for a number: ১ম, ২য়,
+// ৪র্থ, ৬ষ্ঠ, ১১তম.
+func ordinalSuffix(digits string) string {
+	switch strings.TrimLeft(digits, "0") {
+	case "1", "5", "7", "8", "9", "10":
+		return "ম"
+	case "2", "3":
+		return "য়"
+	case "4":
+		return "র্থ"
+	case "6":
+		return "ষ্ঠ"
+	}
+	return "তম"
+}
+
+func isASCIIDigit(ch rune) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isASCIIAlnum(ch rune) bool {
+	return isASCIIDigit(ch) || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
@@ -0,0 +1,113 @@
+package phonetic
+
+import "testing"
+
+func TestGroupLakhs(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   string
+	}{
+		{"1", "1"},
+		{"999", "999"},
+		{"1000", "1,000"},
+		{"12345", "12,345"},
+		{"123456", "1,23,456"},
+		{"1234567", "12,34,567"},
+		{"12345678", "1,23,45,678"},
+		{"1234567890", "1,23,45,67,890"},
+	}
+	for _, tt := range tests {
+		if got := groupLakhs(tt.digits); got != tt.want {
+			t.Errorf("groupLakhs(%q) = %q, want %q", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestNumberFormatting(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		// Four digits stay ungrouped, longer numbers go in lakhs and crores
+		{"1234", "১২৩৪"},
+		{"12345", "১২,৩৪৫"},
+		{"1500000", "১৫,০০,০০০"},
+		{"123456789", "১২,৩৪,৫৬,৭৮৯"},
+		{"1234567.89", "১২,৩৪,৫৬৭.৮৯"},
+
+		// Amounts from a thousand up, with the taka sign for $
+		{"$1500", "৳১,৫০০"},
+		{"$999", "৳৯৯৯"},
+		{"$1500000", "৳১৫,০০,০০০"},
+		{"$12.50", "৳১২.৫০"},
+
+		// Phone numbers are never grouped
+		{"01712345678", "০১৭১২৩৪৫৬৭৮"},
+	}
+	engine := New(WithNumberFormatting(true))
+	for _, tt := range tests {
+		if got := engine.Convert(tt.input); got != tt.want {
+			t.Errorf("Convert(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	if got, want := New().Convert("1500000"), "১৫০০০০০"; got != want {
+		t.Errorf("Convert(1500000) without number formatting = %q, want %q", got, want)
+	}
+}
+
+func TestOrdinals(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1st", "১ম"},
+		{"2nd", "২য়"},
+		{"3rd", "৩য়"},
+		{"4th", "৪র্থ"},
+		{"5th", "৫ম"},
+		{"6th", "৬ষ্ঠ"},
+		{"7th", "৭ম"},
+		{"10th", "১০ম"},
+		{"11th", "১১তম"},
+		{"21st", "২১তম"},
+		{"100th", "১০০তম"},
+		{"2ND", "২য়"},
+	}
+	engine := New()
+	for _, tt := range tests {
+		if got := engine.Convert(tt.input); got != tt.want {
+			t.Errorf("Convert(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDigitModes(t *testing.T) {
+	modes := []DigitMode{DigitsBengali, DigitsASCII, DigitsContext}
+	tests := []struct {
+		input string
+		want  [3]string // in the order of modes
+	}{
+		{"500", [3]string{"৫০০", "500", "৫০০"}},
+		{"3.14", [3]string{"৩.১৪", "3.14", "৩.১৪"}},
+		{"$1200", [3]string{"৳১২০০", "৳1200", "৳১২০০"}},
+		{"4th", [3]string{"৪র্থ", "4র্থ", "৪র্থ"}},
+		{"ami 12 ta", [3]string{"আমি ১২ তা", "আমি 12 তা", "আমি ১২ তা"}},
+
+		// Times, phone numbers and codes stay ASCII in context mode
+		{"10:30", [3]string{"১০:৩০", "10:30", "10:30"}},
+		{"01712345678", [3]string{"০১৭১২৩৪৫৬৭৮", "01712345678", "01712345678"}},
+		{"0171234", [3]string{"০১৭১২৩৪", "0171234", "0171234"}},
+		{"1712345678", [3]string{"১৭১২৩৪৫৬৭৮", "1712345678", "1712345678"}},
+		{"k4", [3]string{"ক৪", "ক4", "ক4"}},
+		{"12k", [3]string{"১২ক", "12ক", "12ক"}},
+	}
+	for i, mode := range modes {
+		engine := New(WithDigitMode(mode))
+		for _, tt := range tests {
+			if got := engine.Convert(tt.input); got != tt.want[i] {
+				t.Errorf("digit mode %d: Convert(%q) = %q, want %q", mode, tt.input, got, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package phonetic
+
+import (
+	"sync"
+	"time"
+)
+
+// SessionPool keeps one Session per focus context, such as a window or an
+// input method context, so that a word half typed in one place is never
+// converted in another. Sessions idle for longer than the idle timeout are
+// dropped, and the least recently used ones once the pool is full.
+type SessionPool struct {
+	engine      *Engine
+	idleTimeout time.Duration
+	maxSessions int
+	enabled     bool // enabled flag of new sessions
+	sessions    map[uintptr]*pooledSession
+	mutex       sync.Mutex
+	now         func() time.Time
+}
+
+type pooledSession struct {
+	session  *Session
+	lastUsed time.Time
+}
+
+// NewSessionPool returns an empty pool of sessions of the engine. Zero
+// idleTimeout or maxSessions, or less, means no limit.
+func (e *Engine) NewSessionPool(idleTimeout time.Duration, maxSessions int) *SessionPool {
+	return &SessionPool{
+		engine:      e,
+		idleTimeout: idleTimeout,
+		maxSessions: maxSessions,
+		enabled:     true,
+		sessions:    make(map[uintptr]*pooledSession),
+		now:         time.Now,
+	}
+}
+
+// SetDefaultEnabled sets whether sessions created from now on convert.
+func (p *SessionPool) SetDefaultEnabled(enabled bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.enabled = enabled
+}
+
+// Get returns the session of a focus context, creating it if needed.
+func (p *SessionPool) Get(key uintptr) *Session {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	p.evict(now)
+
+	if entry, ok := p.sessions[key]; ok {
+		entry.lastUsed = now
+		return entry.session
+	}
+
+	if p.maxSessions > 0 && len(p.sessions) >= p.maxSessions {
+		p.evictOldest()
+	}
+	session := p.engine.NewSession()
+	session.enabled = p.enabled
+	p.sessions[key] = &pooledSession{session: session, lastUsed: now}
+	return session
+}
+
+// Remove drops the session of a focus context that no longer exists.
+func (p *SessionPool) Remove(key uintptr) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.sessions, key)
+}
+
+// Len returns the number of sessions in the pool.
+func (p *SessionPool) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.sessions)
+}
+
+func (p *SessionPool) evict(now time.Time) {
+	if p.idleTimeout <= 0 {
+		return
+	}
+	for key, entry := range p.sessions {
+		if now.Sub(entry.lastUsed) > p.idleTimeout {
+			delete(p.sessions, key)
+		}
+	}
+}
+
+func (p *SessionPool) evictOldest() {
+	var oldestKey uintptr
+	var oldest *pooledSession
+	for key, entry := range p.sessions {
+		if oldest == nil || entry.lastUsed.Before(oldest.lastUsed) {
+			oldestKey, oldest = key, entry
+		}
+	}
+	if oldest != nil {
+		delete(p.sessions, oldestKey)
+	}
+}
+
+// SetEngine makes the pool's sessions convert with engine from now on,
+// e.g. after switching schemes. Words being typed are dropped, since they
+// were buffered for the old scheme.
+func (p *SessionPool) SetEngine(engine *Engine) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.engine = engine
+	for _, entry := range p.sessions {
+		entry.session.setEngine(engine)
+	}
+}
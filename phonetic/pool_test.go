@@ -0,0 +1,105 @@
+package phonetic
+
+import (
+	"testing"
+	"time"
+)
+
+// testClock is a time source for SessionPool.now that only moves when told.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func (c *testClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestPool(idleTimeout time.Duration, maxSessions int) (*SessionPool, *testClock) {
+	clock := &testClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	p := New().NewSessionPool(idleTimeout, maxSessions)
+	p.now = clock.Now
+	return p, clock
+}
+
+func TestSessionPoolIdleExpiry(t *testing.T) {
+	p, clock := newTestPool(time.Minute, 0)
+
+	first := p.Get(1)
+	typeText(first, "am")
+
+	// Idle for exactly the timeout, the session is kept
+	clock.advance(time.Minute)
+	p.Get(2)
+	if p.Len() != 2 {
+		t.Fatalf("Len() = %d after idling for the timeout, want 2", p.Len())
+	}
+
+	// Idle past it, the session is dropped on the next Get
+	clock.advance(time.Nanosecond)
+	p.Get(2)
+	if p.Len() != 1 {
+		t.Errorf("Len() = %d after idling past the timeout, want 1", p.Len())
+	}
+	if s := p.Get(1); s == first || s.Buffer() != "" {
+		t.Errorf("Get after expiry returned the old session, buffer %q", s.Buffer())
+	}
+}
+
+func TestSessionPoolUseKeepsSessionAlive(t *testing.T) {
+	p, clock := newTestPool(time.Minute, 0)
+
+	first := p.Get(1)
+	for i := 0; i < 5; i++ {
+		clock.advance(50 * time.Second)
+		if s := p.Get(1); s != first {
+			t.Fatalf("Get %d: session in use replaced", i)
+		}
+	}
+}
+
+func TestSessionPoolCapacity(t *testing.T) {
+	p, clock := newTestPool(0, 2)
+
+	one := p.Get(1)
+	clock.advance(time.Second)
+	two := p.Get(2)
+	clock.advance(time.Second)
+	p.Get(1) // 2 is now the least recently used
+	clock.advance(time.Second)
+	p.Get(3)
+
+	if p.Len() != 2 {
+		t.Errorf("Len() = %d at capacity, want 2", p.Len())
+	}
+	if s := p.Get(1); s != one {
+		t.Error("recently used session 1 was evicted")
+	}
+	if s := p.Get(2); s == two {
+		t.Error("least recently used session 2 was kept")
+	}
+}
+
+func TestSessionPoolFreshSession(t *testing.T) {
+	p, _ := newTestPool(0, 1)
+
+	old := p.Get(1)
+	typeText(old, "ami tu")
+	old.SetEnabled(false)
+	p.SetDefaultEnabled(true)
+
+	p.Get(2) // evicts 1
+	s := p.Get(1)
+	if s == old {
+		t.Fatal("Get after eviction returned the evicted session")
+	}
+	if s.Buffer() != "" || len(s.History()) != 0 || !s.Enabled() {
+		t.Errorf("session after eviction: buffer %q, history %q, enabled %v, want a fresh enabled one",
+			s.Buffer(), s.History(), s.Enabled())
+	}
+
+	p.SetDefaultEnabled(false)
+	p.Remove(1)
+	if p.Get(1).Enabled() {
+		t.Error("new session enabled after SetDefaultEnabled(false)")
+	}
+}
@@ -0,0 +1,81 @@
+package phonetic
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// reverseMap turns Bengali text back into the Latin patterns of a keymap.
+type reverseMap struct {
+	latin  map[string]string // Bengali -> shortest Latin pattern producing it
+	maxLen int               // longest Bengali key, in runes
+}
+
+func newReverseMap(km *KeyMap) *reverseMap {
+	rm := &reverseMap{latin: make(map[string]string)}
+
+	add := func(bengali, pattern string) {
+		bengali = Normalize(bengali, NFC)
+		if bengali == "" {
+			return
+		}
+		if existing, ok := rm.latin[bengali]; ok {
+			// Prefer the shortest pattern, then the lowest for a stable choice
+			if len(existing) < len(pattern) || (len(existing) == len(pattern) && existing < pattern) {
+				return
+			}
+		}
+		rm.latin[bengali] = pattern
+		if n := utf8.RuneCountInString(bengali); n > rm.maxLen {
+			rm.maxLen = n
+		}
+	}
+
+	for pattern, bengaliChar := range km.Patterns {
+		add(bengaliChar.Bengali, pattern)
+	}
+	for pattern, diacritic := range km.VowelDiacritics {
+		add(diacritic, pattern)
+	}
+	return rm
+}
+
+// Reverse transliterates Bengali text back to the Latin input that
+// produces it, adding the inherent "o" between consonants.
+func (e *Engine) Reverse(text string) string {
+	rm := e.reverse
+	var result strings.Builder
+	chars := []rune(Normalize(text, NFC))
+	afterConsonant := false
+	i := 0
+
+	for i < len(chars) {
+		matched := 0
+		latin := ""
+		for n := min(rm.maxLen, len(chars)-i); n > 0; n-- {
+			if pattern, ok := rm.latin[string(chars[i:i+n])]; ok {
+				matched = n
+				latin = pattern
+				break
+			}
+		}
+
+		if matched == 0 {
+			if chars[i] != '্' { // hasanta joins consonants, so no inherent vowel
+				result.WriteRune(chars[i])
+			}
+			afterConsonant = false
+			i++
+			continue
+		}
+
+		if afterConsonant && isBengaliConsonant(chars[i]) {
+			result.WriteString("o")
+		}
+		result.WriteString(latin)
+		afterConsonant = endsWithConsonant(string(chars[i : i+matched]))
+		i += matched
+	}
+
+	return result.String()
+}
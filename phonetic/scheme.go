@@ -0,0 +1,189 @@
+package phonetic
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Scheme is a keymap with the name it is selected by and a title and
+// description to show people choosing one.
+type Scheme struct {
+	Name        string
+	Title       string
+	Description string
+	KeyMap      *KeyMap
+
+	engine *Engine
+}
+
+// Engine returns the engine converting with the scheme, once registered.
+func (s *Scheme) Engine() *Engine {
+	return s.engine
+}
+
+// BuiltinSchemes returns the schemes of the built-in keymaps, default
+// first.
+func BuiltinSchemes() []Scheme {
+	schemes := make([]Scheme, 0, len(keymaps))
+	for _, k := range keymaps {
+		schemes = append(schemes, Scheme{Name: k.name, Title: k.title, Description: k.description, KeyMap: k.build()})
+	}
+	return schemes
+}
+
+// SchemeRegistry holds the schemes that can be switched between while
+// typing, each with its own engine. Schemes cycle in the order they were
+// registered unless SetOrder changes it. The current scheme changes
+// atomically, so a key is converted by the engine of one scheme or the
+// other. It is safe for concurrent use.
+type SchemeRegistry struct {
+	opts    []Option
+	schemes map[string]*Scheme
+	order   []*Scheme
+	def     *Scheme
+	current atomic.Pointer[Scheme]
+	mutex   sync.Mutex
+}
+
+// NewSchemeRegistry returns an empty registry whose engines are created
+// with opts and the keymap of their scheme.
+func NewSchemeRegistry(opts ...Option) *SchemeRegistry {
+	return &SchemeRegistry{opts: opts, schemes: make(map[string]*Scheme)}
+}
+
+// Register adds a scheme at the end of the cycling order. The first one
+// registered is the default and current scheme until changed.
+func (r *SchemeRegistry) Register(scheme Scheme) error {
+	if scheme.Name == "" {
+		return errors.New("scheme without a name")
+	}
+	if scheme.KeyMap == nil {
+		return fmt.Errorf("scheme %q has no keymap", scheme.Name)
+	}
+	if scheme.Title == "" {
+		scheme.Title = scheme.Name
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.schemes[scheme.Name]; ok {
+		return fmt.Errorf("scheme %q is already registered", scheme.Name)
+	}
+	scheme.engine = New(append(r.opts[:len(r.opts):len(r.opts)], WithKeyMap(scheme.KeyMap))...)
+	s := &scheme
+	r.schemes[s.Name] = s
+	r.order = append(r.order, s)
+	if r.def == nil {
+		r.def = s
+		r.current.Store(s)
+	}
+	return nil
+}
+
+// Lookup returns the scheme registered under name.
+func (r *SchemeRegistry) Lookup(name string) (*Scheme, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	s, ok := r.schemes[name]
+	return s, ok
+}
+
+// Names lists the names of every registered scheme in sorted order.
+func (r *SchemeRegistry) Names() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	names := make([]string, 0, len(r.schemes))
+	for name := range r.schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Schemes lists the schemes in cycling order.
+func (r *SchemeRegistry) Schemes() []*Scheme {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]*Scheme(nil), r.order...)
+}
+
+// SetOrder makes Next cycle through the named schemes only, in the order
+// given.
+func (r *SchemeRegistry) SetOrder(names []string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	order := make([]*Scheme, 0, len(names))
+	for _, name := range names {
+		s, ok := r.schemes[name]
+		if !ok {
+			return fmt.Errorf("unknown scheme %q", name)
+		}
+		order = append(order, s)
+	}
+	if len(order) == 0 {
+		return errors.New("no schemes to cycle through")
+	}
+	r.order = order
+	return nil
+}
+
+// Default returns the scheme used at start, nil before any is registered.
+func (r *SchemeRegistry) Default() *Scheme {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.def
+}
+
+// SetDefault makes a scheme the default and current one.
+func (r *SchemeRegistry) SetDefault(name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	s, ok := r.schemes[name]
+	if !ok {
+		return fmt.Errorf("unknown scheme %q", name)
+	}
+	r.def = s
+	r.current.Store(s)
+	return nil
+}
+
+// Current returns the scheme typing uses now.
+func (r *SchemeRegistry) Current() *Scheme {
+	return r.current.Load()
+}
+
+// Switch makes the named scheme current.
+func (r *SchemeRegistry) Switch(name string) (*Scheme, error) {
+	s, ok := r.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown scheme %q", name)
+	}
+	r.current.Store(s)
+	return s, nil
+}
+
+// Next makes the scheme after the current one in the cycling order
+// current and returns it. A current scheme outside the order moves to the
+// first one.
+func (r *SchemeRegistry) Next() *Scheme {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(r.order) == 0 {
+		return nil
+	}
+
+	next := r.order[0]
+	current := r.current.Load()
+	for i, s := range r.order {
+		if s == current {
+			next = r.order[(i+1)%len(r.order)]
+			break
+		}
+	}
+	r.current.Store(next)
+	return next
+}
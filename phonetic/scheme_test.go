@@ -0,0 +1,214 @@
+package phonetic
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// newTestRegistry registers the built-in schemes and a third one that
+// types x as ক.
+func newTestRegistry(t *testing.T, opts ...Option) *SchemeRegistry {
+	t.Helper()
+	r := NewSchemeRegistry(opts...)
+	for _, scheme := range BuiltinSchemes() {
+		if err := r.Register(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keymap := NewKeyMap()
+	keymap.Patterns["x"] = BengaliChar{Bengali: "ক"}
+	if err := r.Register(Scheme{Name: "mine", KeyMap: keymap}); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func schemeNames(schemes []*Scheme) []string {
+	var names []string
+	for _, s := range schemes {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestSchemeRegister(t *testing.T) {
+	r := newTestRegistry(t, WithDigitMode(DigitsASCII))
+
+	if got, want := r.Names(), []string{"avro", "mine", "phonetic"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %q, want %q", got, want)
+	}
+	if got, want := schemeNames(r.Schemes()), []string{"phonetic", "avro", "mine"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Schemes() = %q, want registration order %q", got, want)
+	}
+	if r.Default().Name != DefaultKeyMapName || r.Current() != r.Default() {
+		t.Errorf("first registered scheme is not the default and current one: %q, %q", r.Default().Name, r.Current().Name)
+	}
+
+	mine, ok := r.Lookup("mine")
+	if !ok {
+		t.Fatal("Lookup(mine) failed")
+	}
+	if mine.Title != "mine" {
+		t.Errorf("Title = %q, want the name for a scheme registered without one", mine.Title)
+	}
+
+	// Each engine has its scheme's keymap and the registry's options
+	for name, want := range map[string]string{"phonetic": "ষx1", "avro": "শক্স1", "mine": "ষক1"} {
+		scheme, _ := r.Lookup(name)
+		if got := scheme.Engine().Convert("shx1"); got != want {
+			t.Errorf("%s engine: Convert(shx1) = %q, want %q", name, got, want)
+		}
+	}
+
+	for _, tt := range []struct {
+		name   string
+		scheme Scheme
+	}{
+		{"no name", Scheme{KeyMap: NewKeyMap()}},
+		{"no keymap", Scheme{Name: "empty"}},
+		{"duplicate", Scheme{Name: "mine", KeyMap: NewKeyMap()}},
+	} {
+		if err := r.Register(tt.scheme); err == nil {
+			t.Errorf("Register with %s succeeded", tt.name)
+		}
+	}
+	if _, ok := r.Lookup("empty"); ok {
+		t.Error("scheme that failed to register was added")
+	}
+}
+
+func TestSchemeNext(t *testing.T) {
+	r := newTestRegistry(t)
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, r.Next().Name)
+	}
+	if want := []string{"avro", "mine", "phonetic", "avro"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Next cycled through %q, want %q", got, want)
+	}
+	if r.Current().Name != "avro" {
+		t.Errorf("Current() = %q after Next, want avro", r.Current().Name)
+	}
+
+	if err := r.SetOrder([]string{"mine", "avro"}); err != nil {
+		t.Fatal(err)
+	}
+	got = got[:0]
+	for i := 0; i < 3; i++ {
+		got = append(got, r.Next().Name)
+	}
+	if want := []string{"mine", "avro", "mine"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Next in the set order cycled through %q, want %q", got, want)
+	}
+
+	// A current scheme outside the order moves to its first one
+	if _, err := r.Switch("phonetic"); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Next().Name; got != "mine" {
+		t.Errorf("Next from a scheme outside the order = %q, want mine", got)
+	}
+
+	if err := r.SetOrder([]string{"mine", "nope"}); err == nil {
+		t.Error("SetOrder with an unknown scheme succeeded")
+	}
+	if err := r.SetOrder(nil); err == nil {
+		t.Error("SetOrder without schemes succeeded")
+	}
+	if got := schemeNames(r.Schemes()); !reflect.DeepEqual(got, []string{"mine", "avro"}) {
+		t.Errorf("failed SetOrder changed the order to %q", got)
+	}
+
+	if NewSchemeRegistry().Next() != nil {
+		t.Error("Next on an empty registry returned a scheme")
+	}
+}
+
+func TestSchemeDefaultAndSwitch(t *testing.T) {
+	r := newTestRegistry(t)
+
+	if err := r.SetDefault("avro"); err != nil {
+		t.Fatal(err)
+	}
+	if r.Default().Name != "avro" || r.Current().Name != "avro" {
+		t.Errorf("after SetDefault(avro): default %q, current %q", r.Default().Name, r.Current().Name)
+	}
+
+	scheme, err := r.Switch("mine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scheme.Name != "mine" || r.Current() != scheme {
+		t.Errorf("Switch(mine) returned %q, current %q", scheme.Name, r.Current().Name)
+	}
+	if r.Default().Name != "avro" {
+		t.Errorf("Switch changed the default to %q", r.Default().Name)
+	}
+
+	if err := r.SetDefault("nope"); err == nil {
+		t.Error("SetDefault with an unknown scheme succeeded")
+	}
+	if _, err := r.Switch("nope"); err == nil {
+		t.Error("Switch to an unknown scheme succeeded")
+	}
+	if r.Current().Name != "mine" {
+		t.Errorf("failed Switch changed the current scheme to %q", r.Current().Name)
+	}
+}
+
+func TestSchemeSwitchLiveSessions(t *testing.T) {
+	r := newTestRegistry(t)
+	pool := r.Current().Engine().NewSessionPool(0, 0)
+	first, second := pool.Get(1), pool.Get(2)
+	typeText(first, "s")
+	typeText(second, "ami k")
+
+	avro, err := r.Switch("avro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.SetEngine(avro.Engine())
+
+	// Half typed words are dropped rather than converted with avro
+	for key, s := range map[uintptr]*Session{1: first, 2: second} {
+		if s.Buffer() != "" {
+			t.Errorf("session %d kept buffer %q across the switch", key, s.Buffer())
+		}
+	}
+	if got := typeText(first, "sh "); got != "শ " {
+		t.Errorf("typing sh after the switch = %q, want শ", got)
+	}
+	if got := typeText(pool.Get(3), "sh "); got != "শ " {
+		t.Errorf("typing sh in a new session = %q, want শ", got)
+	}
+}
+
+func TestSchemeConcurrentSwitch(t *testing.T) {
+	r := newTestRegistry(t)
+	registered := make(map[*Scheme]bool)
+	for _, name := range r.Names() {
+		s, _ := r.Lookup(name)
+		registered[s] = true
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			r.Next()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			if s := r.Current(); !registered[s] || s.Engine() == nil {
+				t.Errorf("Current() returned %+v while switching", s)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,359 @@
+package phonetic
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Edit is the change to make to the text before the caret: erase
+// Backspaces characters, then type Text.
+type Edit struct {
+	Backspaces int
+	Text       string
+}
+
+// IsEmpty reports whether the edit changes nothing.
+func (e Edit) IsEmpty() bool {
+	return e.Backspaces == 0 && e.Text == ""
+}
+
+// Number of committed words a Session remembers.
+const historySize = 16
+
+// Session holds the typing state of one text input: whether conversion is
+// enabled, the Latin word typed so far, what has been shown for it and the
+// words committed recently. It is safe for concurrent use.
+type Session struct {
+	engine            *Engine
+	enabled           bool
+	inputBuffer       string
+	lastBengaliOutput string
+	history           []string
+	escaping          bool        // inside an escape, typing literal text
+	escapedRunes      int         // characters typed since the escape started
+	justConverted     bool        // the last key committed a conversion
+	misspelled        bool        // the spell checker flagged the word the last key committed
+	correction        *correction // autocorrection made by the last key
+	mutex             sync.Mutex
+}
+
+// NewSession returns an enabled session with nothing typed yet.
+func (e *Engine) NewSession() *Session {
+	return &Session{engine: e, enabled: true}
+}
+
+// Enabled reports whether the session converts what is typed.
+func (s *Session) Enabled() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.enabled
+}
+
+// SetEnabled turns conversion on or off, dropping the current word.
+func (s *Session) SetEnabled(enabled bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.enabled = enabled
+	s.dropWord()
+}
+
+// History returns the most recently committed Bengali words, oldest first.
+func (s *Session) History() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.history...)
+}
+
+func (s *Session) remember(word string) {
+	if len(s.history) == historySize {
+		s.history = append(s.history[:0], s.history[1:]...)
+	}
+	s.history = append(s.history, word)
+}
+
+// LastWordMisspelled reports whether the spell checker flagged a word the
+// last key committed, or brought back by taking an autocorrection back.
+// The word is the last one in History. It is false after any other key.
+func (s *Session) LastWordMisspelled() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.misspelled
+}
+
+// Buffer returns the Latin text typed since the last word boundary.
+func (s *Session) Buffer() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.inputBuffer
+}
+
+// Reset forgets the current word, e.g. when the caret moves elsewhere.
+func (s *Session) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.dropWord()
+}
+
+func (s *Session) setEngine(engine *Engine) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.engine = engine
+	s.dropWord()
+}
+
+// dropWord forgets the word being typed and what the last key did to the
+// text before it, which a backspace could otherwise undo in text that is
+// no longer there. The caller holds the mutex.
+func (s *Session) dropWord() {
+	s.inputBuffer = ""
+	s.lastBengaliOutput = ""
+	s.escaping = false
+	s.justConverted = false
+	s.correction = nil
+}
+
+// Process feeds one typed character through the buffer state machine.
+// It returns the edit to apply and whether the original key must be
+// suppressed. A disabled session lets every key through.
+func (s *Session) Process(ch rune) (Edit, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.enabled {
+		return Edit{}, false
+	}
+
+	// Erasing right after a conversion counts as undoing it
+	if s.justConverted && ch == '\b' {
+		s.engine.metrics.recordUndo()
+	}
+	s.justConverted = false
+	s.misspelled = false
+
+	// Erasing right after an autocorrection brings back the literal conversion
+	if c := s.correction; c != nil && ch == '\b' {
+		s.correction = nil
+		s.history[len(s.history)-1] = c.literal
+		s.misspelled = s.engine.Misspelled(c.literal)
+		return Edit{
+			Backspaces: utf8.RuneCountInString(c.corrected + c.trailing),
+			Text:       c.literal,
+		}, true
+	}
+	s.correction = nil
+
+	edit, suppress := s.process(ch)
+	if c := s.correction; c != nil {
+		c.trailing = strings.TrimPrefix(edit.Text, c.corrected)
+	}
+	return edit, suppress
+}
+
+func (s *Session) process(ch rune) (Edit, bool) {
+	if s.escaping {
+		return s.processEscaped(ch)
+	} else if ch == EscapeChar {
+		// Finish the pending word and pass what follows through verbatim
+		edit := s.finishWord()
+		s.escaping = true
+		s.escapedRunes = 0
+		return edit, true
+	}
+
+	// Keep a possible expansion token together, even through characters
+	// that would otherwise end the word
+	if token := s.inputBuffer + string(ch); s.engine.isExpansionPrefix(token) && !s.engine.isWord(token) {
+		s.inputBuffer += string(ch)
+		if s.engine.mode == ModeLive {
+			return s.rerender(), true
+		}
+		return Edit{}, false
+	} else if s.engine.isInputChar(ch) && !s.engine.isWord(s.inputBuffer) {
+		// The token did not become an expansion; a new word starts here
+		s.inputBuffer = ""
+		s.lastBengaliOutput = ""
+	}
+
+	if s.engine.mode == ModeLive {
+		return s.processLive(ch)
+	}
+
+	if ch == '\b' { // Backspace
+		if len(s.inputBuffer) > 0 {
+			runes := []rune(s.inputBuffer)
+			s.inputBuffer = string(runes[:len(runes)-1])
+		}
+		return Edit{}, false
+	} else if s.engine.isCommitTrigger(ch) {
+		// Word boundary - process current word
+		edit := s.finishWord()
+
+		// Send the character that triggered the conversion after it
+		punctuation := s.engine.punctuation(ch)
+		if edit.IsEmpty() && punctuation == string(ch) {
+			return Edit{}, false // Allow the trigger character
+		}
+		edit.Text += punctuation
+		return edit, true
+	} else if s.engine.isInputChar(ch) {
+		// Add character to buffer but don't convert yet
+		s.inputBuffer += string(ch)
+		return Edit{}, false // Allow the character to be typed normally
+	} else {
+		// Non-matching character, clear buffer
+		s.inputBuffer = ""
+		s.lastBengaliOutput = ""
+		return Edit{}, false // Allow the character
+	}
+}
+
+// processLive keeps the conversion of the current word on screen, replacing
+// lastBengaliOutput with the new conversion on every keystroke.
+func (s *Session) processLive(ch rune) (Edit, bool) {
+	if ch == '\b' && len(s.inputBuffer) > 0 {
+		runes := []rune(s.inputBuffer)
+		s.inputBuffer = string(runes[:len(runes)-1])
+		return s.rerender(), true
+	} else if s.engine.isInputChar(ch) {
+		s.inputBuffer += string(ch)
+		return s.rerender(), true
+	}
+
+	// Anything else ends the word as it is shown
+	edit := s.finishWord()
+
+	if s.engine.isCommitTrigger(ch) {
+		if punctuation := s.engine.punctuation(ch); punctuation != string(ch) || !edit.IsEmpty() {
+			edit.Text += punctuation
+			return edit, true
+		}
+	}
+	if !edit.IsEmpty() {
+		edit.Text += string(ch)
+		return edit, true
+	}
+	return Edit{}, false
+}
+
+// finishWord ends the word being typed and returns the edit replacing it
+// with its conversion, if it is not already shown.
+func (s *Session) finishWord() Edit {
+	word := s.inputBuffer
+	shown := s.lastBengaliOutput
+
+	// Clear buffer even if no conversion happens
+	s.inputBuffer = ""
+	s.lastBengaliOutput = ""
+
+	if expansion, ok := s.engine.expansion(word); ok {
+		typed := utf8.RuneCountInString(word)
+		if s.engine.mode == ModeLive {
+			typed = utf8.RuneCountInString(shown)
+		}
+		return Edit{Backspaces: typed, Text: Normalize(expansion, s.engine.normForm)}
+	}
+	if !s.engine.isWord(word) {
+		return Edit{} // an unfinished expansion token stays as typed
+	}
+
+	if s.engine.mode == ModeLive {
+		if shown != "" && s.engine.isEnglish(word) {
+			// Put back the English word shown converted while typing
+			return Edit{
+				Backspaces: utf8.RuneCountInString(shown),
+				Text:       word,
+			}
+		}
+		if shown != "" {
+			corrected := s.autocorrect(word, shown)
+			s.remember(corrected)
+			s.recordConversion(word)
+			s.misspelled = s.engine.Misspelled(corrected)
+			if corrected != shown {
+				return Edit{
+					Backspaces: utf8.RuneCountInString(shown),
+					Text:       corrected,
+				}
+			}
+		}
+		return Edit{}
+	}
+
+	if len(word) > 0 && !s.engine.isEnglish(word) {
+		bengaliWord := s.engine.Convert(word)
+
+		// If we have a valid Bengali conversion and it's different from input
+		if len(bengaliWord) > 0 && bengaliWord != word {
+			bengaliWord = s.autocorrect(word, bengaliWord)
+			s.remember(bengaliWord)
+			s.recordConversion(word)
+			s.misspelled = s.engine.Misspelled(bengaliWord)
+
+			// Remove the English word and send the Bengali word
+			return Edit{
+				Backspaces: utf8.RuneCountInString(word),
+				Text:       bengaliWord,
+			}
+		}
+	}
+	return Edit{}
+}
+
+// autocorrect returns the preferred spelling of a word being committed and
+// keeps the literal conversion for taking the correction back.
+func (s *Session) autocorrect(word, bengali string) string {
+	corrected := s.engine.corrected(word, bengali)
+	if corrected != bengali {
+		s.correction = &correction{literal: bengali, corrected: corrected}
+	}
+	return corrected
+}
+
+func (s *Session) recordConversion(word string) {
+	s.justConverted = true
+	if s.engine.metrics != nil {
+		_, matches := s.engine.ConvertTrace(word)
+		s.engine.metrics.recordConversion(matches)
+	}
+}
+
+// processEscaped lets keys through unconverted until the closing escape
+// character or the end of the line. An empty escape types the escape
+// character itself.
+func (s *Session) processEscaped(ch rune) (Edit, bool) {
+	switch ch {
+	case EscapeChar:
+		s.escaping = false
+		if s.escapedRunes == 0 {
+			return Edit{Text: string(EscapeChar)}, true
+		}
+		return Edit{}, true
+	case '\n':
+		s.escaping = false
+	case '\b':
+		if s.escapedRunes == 0 {
+			s.escaping = false // erased back past the start of the escape
+		} else {
+			s.escapedRunes--
+		}
+	default:
+		s.escapedRunes++
+	}
+	return Edit{}, false
+}
+
+func (s *Session) rerender() Edit {
+	output := s.engine.convert(s.inputBuffer, false, nil)
+	edit := Edit{
+		Backspaces: utf8.RuneCountInString(s.lastBengaliOutput),
+		Text:       output,
+	}
+	s.lastBengaliOutput = output
+	return edit
+}
+
+func isValidInputChar(ch rune) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') ||
+		(ch >= '0' && ch <= '9') || ch == '.' || ch == ':' || ch == '$' || ch == '_'
+}
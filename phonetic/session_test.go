@@ -0,0 +1,217 @@
+package phonetic
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// typeText feeds input through a session the way the keyboard does and
+// returns the text an editor would end up with.
+func typeText(s *Session, input string) string {
+	var text []rune
+	erase := func(n int) {
+		text = text[:max(len(text)-n, 0)]
+	}
+	for _, ch := range input {
+		edit, suppress := s.Process(ch)
+		erase(edit.Backspaces)
+		text = append(text, []rune(edit.Text)...)
+		switch {
+		case suppress:
+		case ch == '\b':
+			erase(1)
+		default:
+			text = append(text, ch)
+		}
+	}
+	return string(text)
+}
+
+func TestSessionEscapes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"a`b`c ", "আbচ "},
+		{"ami `hello` tumi ", "আমি hello তুমি "},
+		{"`` ", "` "},
+		{"ami `` ", "আমি ` "},
+		{"ami `hello tumi ", "আমি hello tumi "},
+		{"`hello\ntumi ", "hello\nতুমি "},
+		{"`ab\b\bc` ami ", "c আমি "},
+	}
+	for _, mode := range []Mode{ModeWord, ModeLive} {
+		engine := New(WithMode(mode))
+		for _, tt := range tests {
+			if got := typeText(engine.NewSession(), tt.input); got != tt.want {
+				t.Errorf("mode %d: typing %q gave %q, want %q", mode, tt.input, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestSessionAutocorrectUndo(t *testing.T) {
+	engine := New(WithAutocorrect(DefaultAutocorrect()))
+
+	s := engine.NewSession()
+	if got, want := typeText(s, "bangladesh \b"), "বাংলাদেষ"; got != want {
+		t.Errorf("backspace after the correction: got %q, want %q", got, want)
+	}
+
+	// After the caret moves or conversion is toggled, a backspace erases
+	// one character of whatever text is there now
+	for name, drop := range map[string]func(*Session){
+		"Reset":      (*Session).Reset,
+		"SetEnabled": func(s *Session) { s.SetEnabled(true) },
+	} {
+		s := engine.NewSession()
+		typeText(s, "bangladesh ")
+		drop(s)
+		if edit, suppress := s.Process('\b'); edit != (Edit{}) || suppress {
+			t.Errorf("backspace after %s: got %+v suppress %v, want the key passed through", name, edit, suppress)
+		}
+	}
+}
+
+func TestSessionNormalizesReplacements(t *testing.T) {
+	// User tables and keymaps may hold precomposed ড় (U+09DC), which
+	// conversions never produce
+	const precomposed, decomposed = "বা\u09dcি", "বা\u09a1\u09bcি"
+	table := Autocorrect{precomposed: "বা\u09dcী", "gari": "গা\u09dcি"}
+	keymap := NewKeyMap()
+	keymap.Expansions["::r"] = "\u09dc"
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"baRi ", "বা\u09a1\u09bcী "}, // key matched through its conversion
+		{"gari ", "গা\u09a1\u09bcি "}, // Latin key, value normalized
+		{"::r ", "\u09a1\u09bc "},     // expansion
+	}
+	for _, mode := range []Mode{ModeWord, ModeLive} {
+		for _, form := range []NormForm{NFC, NFD} {
+			engine := New(WithKeyMap(keymap), WithMode(mode), WithNormalization(form), WithAutocorrect(table))
+			for _, tt := range tests {
+				if got := typeText(engine.NewSession(), tt.input); got != tt.want {
+					t.Errorf("mode %d, form %d: typing %q = %+q, want %+q", mode, form, tt.input, got, tt.want)
+				}
+			}
+		}
+	}
+	if _, ok := table[decomposed]; ok {
+		t.Error("WithAutocorrect changed the caller's table")
+	}
+}
+
+func TestCommitTriggers(t *testing.T) {
+	tests := []struct {
+		triggers string // empty for DefaultCommitTriggers
+		input    string
+		want     [3]string // typed in ModeWord, typed in ModeLive, Convert
+	}{
+		{"", "ami,", [3]string{"আমি,", "আমি,", "আমি,"}},
+		{"", "ami? ", [3]string{"আমি? ", "আমি? ", "আমি? "}},
+		{"", "(ami)", [3]string{"(আমি)", "(আমি)", "(আমি)"}},
+		{"", `"ami"`, [3]string{`"আমি"`, `"আমি"`, `"আমি"`}},
+		{"", "[ami]", [3]string{"[আমি]", "[আমি]", "[আমি]"}},
+		{"", "ami;tumi!", [3]string{"আমি;তুমি!", "আমি;তুমি!", "আমি;তুমি!"}},
+		{"", "ami|", [3]string{"আমি।", "আমি।", "আমি।"}},
+
+		// Punctuation is mapped with nothing typed before it as well
+		{"", "|", [3]string{"।", "।", "।"}},
+		{"", "ami |", [3]string{"আমি ।", "আমি ।", "আমি ।"}},
+
+		// - is no trigger by default, so in word mode it drops the word
+		{"", "ami-tumi ", [3]string{"ami-তুমি ", "আমি-তুমি ", "আমি-তুমি "}},
+
+		// Custom triggers replace the default ones
+		{",-", "ami-tumi ", [3]string{"আমি-তুমি ", "আমি-তুমি ", "আমি-তুমি "}},
+		{",-", "ami,", [3]string{"আমি,", "আমি,", "আমি,"}},
+		{",-", "ami?", [3]string{"ami?", "আমি?", "আমি?"}},
+		{",-", "ami|", [3]string{"ami|", "আমি|", "আমি|"}},
+		{",-", "|", [3]string{"|", "|", "|"}},
+	}
+	for _, tt := range tests {
+		var opts []Option
+		if tt.triggers != "" {
+			opts = append(opts, WithCommitTriggers(tt.triggers))
+		}
+		word := New(opts...)
+		live := New(append(opts, WithMode(ModeLive))...)
+
+		got := [3]string{
+			typeText(word.NewSession(), tt.input),
+			typeText(live.NewSession(), tt.input),
+			word.Convert(tt.input),
+		}
+		for i, name := range []string{"word mode", "live mode", "Convert"} {
+			if got[i] != tt.want[i] {
+				t.Errorf("triggers %q, %s: %q = %q, want %q", tt.triggers, name, tt.input, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+// knownWords is a spell checker accepting only its words.
+type knownWords map[string]bool
+
+func (k knownWords) Check(word string) bool { return k[word] }
+
+func (k knownWords) Suggest(string, int, func(a, b string) int) []string { return nil }
+
+func TestSessionLastWordMisspelled(t *testing.T) {
+	for _, mode := range []Mode{ModeWord, ModeLive} {
+		s := New(WithMode(mode), WithSpellChecker(knownWords{"আমি": true})).NewSession()
+
+		// Only the key committing the flagged word reports it
+		var flagged []string
+		for _, ch := range "tumi ami tumi, ke" {
+			s.Process(ch)
+			if s.LastWordMisspelled() {
+				history := s.History()
+				flagged = append(flagged, fmt.Sprintf("%q after %q", history[len(history)-1], ch))
+			}
+		}
+		want := []string{`"তুমি" after ' '`, `"তুমি" after ','`}
+		if !reflect.DeepEqual(flagged, want) {
+			t.Errorf("mode %d: flagged %s, want %s", mode, flagged, want)
+		}
+	}
+}
+
+func TestSessionExpansions(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{":) ", "🙂 "},
+		{"ami :) ", "আমি 🙂 "},
+		{":taka: ", "৳ "},
+		{"<3 ", "❤️ "},
+		{"ami :)\n", "আমি 🙂\n"},
+
+		// Tokens are kept together through commit triggers and punctuation
+		{";) ", "😉 "},
+		{":), ", "🙂, "},
+		{"--, ", "—, "},
+		{":(\b) ", "🙂 "},
+
+		// Inside a word the characters keep their meaning
+		{"ami:) ", "আমিঃ) "},
+		{"ami;) ", "আমি;) "},
+
+		// A token that cannot expand any more gives way to a new word
+		{"<k ", "<ক "},
+		{":) ami, ", "🙂 আমি, "},
+	}
+	for _, mode := range []Mode{ModeWord, ModeLive} {
+		engine := New(WithMode(mode))
+		for _, tt := range tests {
+			if got := typeText(engine.NewSession(), tt.input); got != tt.want {
+				t.Errorf("mode %d: typing %q gave %q, want %q", mode, tt.input, got, tt.want)
+			}
+		}
+	}
+}
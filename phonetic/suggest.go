@@ -0,0 +1,164 @@
+package phonetic
+
+import (
+	"sort"
+	"strings"
+)
+
+// Letters that sound alike and are easily confused when typing phonetically.
+// Suggestions offer the converted word with one of them swapped.
+var confusableLetters = [][]string{
+	{"স", "শ", "ষ"},
+	{"ন", "ণ"},
+	{"জ", "য"},
+	{"ি", "ী"},
+	{"ু", "ূ"},
+	{"ই", "ঈ"},
+	{"উ", "ঊ"},
+	{"র", "ড়"},
+	{"ত", "ৎ"},
+}
+
+// SpellChecker checks Bengali words, such as a spell.Dictionary.
+type SpellChecker interface {
+	Check(word string) bool
+	Suggest(word string, limit int, distance func(a, b string) int) []string
+}
+
+// WithSpellChecker makes the engine flag misspelled words and add the
+// checker's corrections to its suggestions.
+func WithSpellChecker(checker SpellChecker) Option {
+	return func(e *Engine) {
+		e.spell = checker
+	}
+}
+
+// Misspelled reports whether the spell checker rejects a Bengali word.
+// Without a spell checker no word is misspelled.
+func (e *Engine) Misspelled(word string) bool {
+	return e.spell != nil && !e.spell.Check(word)
+}
+
+// Suggestions returns candidate Bengali spellings for a Latin word, the
+// direct conversion first. With a spell checker, correctly spelled
+// variants come next, then the checker's corrections, then the remaining
+// variants. A limit of zero or less returns all candidates.
+func (e *Engine) Suggestions(word string, limit int) []string {
+	converted := e.convert(word, false, nil)
+	candidates := []string{converted}
+	seen := map[string]bool{converted: true}
+
+	add := func(candidate string) {
+		if !seen[candidate] {
+			seen[candidate] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	for _, group := range confusableLetters {
+		for _, letter := range group {
+			for offset := 0; ; {
+				idx := strings.Index(converted[offset:], letter)
+				if idx < 0 {
+					break
+				}
+				idx += offset
+				for _, other := range group {
+					if other != letter {
+						add(converted[:idx] + other + converted[idx+len(letter):])
+					}
+				}
+				offset = idx + len(letter)
+			}
+		}
+	}
+
+	if e.spell != nil {
+		variants := candidates[1:]
+		sort.SliceStable(variants, func(i, j int) bool {
+			return e.spell.Check(variants[i]) && !e.spell.Check(variants[j])
+		})
+		valid := 1 + sort.Search(len(variants), func(i int) bool { return !e.spell.Check(variants[i]) })
+
+		var corrections []string
+		if e.Misspelled(converted) {
+			for _, correction := range e.Corrections(converted, limit) {
+				if !seen[correction] {
+					seen[correction] = true
+					corrections = append(corrections, correction)
+				}
+			}
+		}
+		candidates = append(candidates[:valid:valid], append(corrections, candidates[valid:]...)...)
+	}
+
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
+
+// Corrections returns the spell checker's corrections for a misspelled
+// Bengali word, closest to what was typed first.
+func (e *Engine) Corrections(word string, limit int) []string {
+	if e.spell == nil {
+		return nil
+	}
+	return e.spell.Suggest(word, limit, e.TypingDistance)
+}
+
+// TypingDistance is the edit distance between the Latin input that types
+// two Bengali words, so ন and ণ, typed n and N, are closer than ন and ম.
+func (e *Engine) TypingDistance(a, b string) int {
+	return EditDistance(e.Reverse(a), e.Reverse(b))
+}
+
+// EditDistance is the Levenshtein distance between two strings, counted in
+// characters.
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		diagonal := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			next := min(min(row[j]+1, row[j-1]+1), diagonal+cost)
+			diagonal, row[j] = row[j], next
+		}
+	}
+	return row[len(rb)]
+}
+
+// Predictor suggests the next word from the words committed before it,
+// such as a predict.Model.
+type Predictor interface {
+	PredictWords(history []string, limit int) []string
+}
+
+// WithPredictor makes sessions of the engine predict the next word.
+func WithPredictor(predictor Predictor) Option {
+	return func(e *Engine) {
+		e.predictor = predictor
+	}
+}
+
+// Predictions returns the words most likely to follow the words committed
+// in the session, most likely first. Without a predictor there are none.
+func (s *Session) Predictions(limit int) []string {
+	s.mutex.Lock()
+	predictor := s.engine.predictor
+	history := append([]string(nil), s.history...)
+	s.mutex.Unlock()
+
+	if predictor == nil {
+		return nil
+	}
+	return predictor.PredictWords(history, limit)
+}
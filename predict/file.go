@@ -0,0 +1,128 @@
+package predict
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Models are stored as UTF-8 text:
+//
+//	bengali-keyboard-ngram 1
+//	order 3
+//	12	আমি
+//	5	আমি ভাত
+//	2	আমি ভাত খাই
+//
+// After the header, each line holds a count, a tab and an n-gram of one
+// to order words separated by spaces; the last word is the one counted.
+const (
+	fileMagic   = "bengali-keyboard-ngram"
+	fileVersion = 1
+)
+
+// Load reads a model file.
+func Load(path string) (*Model, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Save writes the model to a file.
+func (m *Model) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := m.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read decodes a model in the file format.
+func Read(r io.Reader) (*Model, error) {
+	scanner := bufio.NewScanner(r)
+	var m *Model
+
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		switch {
+		case line == 1:
+			if text != fmt.Sprintf("%s %d", fileMagic, fileVersion) {
+				return nil, errors.New("not an n-gram model")
+			}
+			continue
+		case line == 2:
+			order, err := strconv.Atoi(strings.TrimPrefix(text, "order "))
+			if err != nil || order < 1 {
+				return nil, fmt.Errorf("line %d: bad order", line)
+			}
+			m = NewModel(order)
+			continue
+		case text == "":
+			continue
+		}
+
+		countText, ngram, found := strings.Cut(text, "\t")
+		count, err := strconv.Atoi(countText)
+		words := strings.Fields(ngram)
+		if !found || err != nil || count < 1 || len(words) == 0 || len(words) > m.order {
+			return nil, fmt.Errorf("line %d: bad n-gram", line)
+		}
+		last := len(words) - 1
+		m.add(strings.Join(words[:last], " "), words[last], count)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, errors.New("not an n-gram model")
+	}
+	return m, nil
+}
+
+// Write encodes the model in the file format, shorter n-grams first.
+func (m *Model) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s %d\norder %d\n", fileMagic, fileVersion, m.order)
+
+	var lines []string
+	for context, next := range m.counts {
+		for word, count := range next {
+			ngram := word
+			if context != "" {
+				ngram = context + " " + word
+			}
+			lines = append(lines, fmt.Sprintf("%d\t%s", count, ngram))
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		ni := strings.Count(lines[i], " ")
+		nj := strings.Count(lines[j], " ")
+		if ni != nj {
+			return ni < nj
+		}
+		_, gi, _ := strings.Cut(lines[i], "\t")
+		_, gj, _ := strings.Cut(lines[j], "\t")
+		return gi < gj
+	})
+	for _, line := range lines {
+		fmt.Fprintln(bw, line)
+	}
+	return bw.Flush()
+}
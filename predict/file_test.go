@@ -0,0 +1,83 @@
+package predict
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	m := NewModel(2)
+	m.AddSentence([]string{"আমি", "ভাত", "খাই"})
+
+	var buf bytes.Buffer
+	if err := m.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "bengali-keyboard-ngram 1\norder 2\n" +
+		"1\tআমি\n1\tখাই\n1\tভাত\n" +
+		"1\tআমি ভাত\n1\tভাত খাই\n"
+	if buf.String() != want {
+		t.Errorf("Write gave\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	m := trainString(t, 3, "আমি ভাত খাই। তুমি ভাত রাঁধো। আমি জল খাই।")
+	path := filepath.Join(t.TempDir(), "bn.ngram")
+	if err := m.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Order() != m.Order() || !reflect.DeepEqual(loaded.counts, m.counts) || !reflect.DeepEqual(loaded.totals, m.totals) {
+		t.Errorf("loaded model differs:\ngot  order %d %v %v\nwant order %d %v %v",
+			loaded.Order(), loaded.counts, loaded.totals, m.Order(), m.counts, m.totals)
+	}
+	history := []string{"আমি"}
+	if got, want := loaded.Predict(history, 0), m.Predict(history, 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded model predicts %v, want %v", got, want)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.ngram")); err == nil {
+		t.Error("Load of a missing file succeeded")
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	const header = "bengali-keyboard-ngram 1\norder 2\n"
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"empty", "", "not an n-gram model"},
+		{"other file", "hello\n", "not an n-gram model"},
+		{"other version", "bengali-keyboard-ngram 2\norder 2\n", "not an n-gram model"},
+		{"header only", "bengali-keyboard-ngram 1\n", "not an n-gram model"},
+		{"bad order", "bengali-keyboard-ngram 1\norder x\n", "line 2: bad order"},
+		{"zero order", "bengali-keyboard-ngram 1\norder 0\n", "line 2: bad order"},
+		{"no tab", header + "3 আমি\n", "line 3: bad n-gram"},
+		{"bad count", header + "x\tআমি\n", "line 3: bad n-gram"},
+		{"zero count", header + "0\tআমি\n", "line 3: bad n-gram"},
+		{"no words", header + "3\t \n", "line 3: bad n-gram"},
+		{"too long", header + "1\tআমি\n1\tআমি ভাত খাই\n", "line 4: bad n-gram"},
+	}
+	for _, tt := range tests {
+		_, err := Read(strings.NewReader(tt.data))
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	// Blank lines are skipped
+	m, err := Read(strings.NewReader(header + "\n2\tআমি\n\n"))
+	if err != nil || m.counts[""]["আমি"] != 2 {
+		t.Errorf("model with blank lines: got %v, %v", m, err)
+	}
+}
@@ -0,0 +1,102 @@
+package predict
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultIndexSize is the number of words an Index keeps when built
+// without a size. At a few dozen bytes a word it stays within a few MB.
+const DefaultIndexSize = 50000
+
+// Index completes words from a prefix of their key, such as
+// phonetic.LatinKey of the letters typed so far. It holds the most
+// frequent words only, sorted by key, so a lookup is a binary search and a
+// scan of the matching range. It is safe for concurrent use.
+type Index struct {
+	entries []indexEntry
+}
+
+type indexEntry struct {
+	key   string
+	word  string
+	count int
+}
+
+// NewIndex indexes the size most frequent of words, which maps words to
+// their counts, under their keys. Words with an empty key are left out.
+// A size of zero or less uses DefaultIndexSize.
+func NewIndex(words map[string]int, key func(word string) string, size int) *Index {
+	if size <= 0 {
+		size = DefaultIndexSize
+	}
+	entries := make([]indexEntry, 0, len(words))
+	for word, count := range words {
+		entries = append(entries, indexEntry{word: word, count: count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].before(entries[j])
+	})
+	if len(entries) > size {
+		entries = entries[:size]
+	}
+
+	keyed := entries[:0]
+	for _, e := range entries {
+		if e.key = key(e.word); e.key != "" {
+			keyed = append(keyed, e)
+		}
+	}
+	entries = append([]indexEntry(nil), keyed...)
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].key != entries[j].key {
+			return entries[i].key < entries[j].key
+		}
+		return entries[i].before(entries[j])
+	})
+	return &Index{entries: entries}
+}
+
+// before orders entries by count, then by word for a stable order.
+func (e indexEntry) before(other indexEntry) bool {
+	if e.count != other.count {
+		return e.count > other.count
+	}
+	return e.word < other.word
+}
+
+func (x *Index) Len() int {
+	return len(x.entries)
+}
+
+// Complete returns up to limit words whose key starts with prefix, most
+// frequent first. A limit of zero or less returns them all.
+func (x *Index) Complete(prefix string, limit int) []string {
+	start := sort.Search(len(x.entries), func(i int) bool {
+		return x.entries[i].key >= prefix
+	})
+
+	// Keep the best limit entries of the range, best first
+	var best []indexEntry
+	for _, e := range x.entries[start:] {
+		if !strings.HasPrefix(e.key, prefix) {
+			break
+		}
+		if limit > 0 && len(best) == limit {
+			if !e.before(best[limit-1]) {
+				continue
+			}
+			best = best[:limit-1]
+		}
+		i := sort.Search(len(best), func(i int) bool { return e.before(best[i]) })
+		best = append(best, indexEntry{})
+		copy(best[i+1:], best[i:])
+		best[i] = e
+	}
+
+	words := make([]string, len(best))
+	for i, e := range best {
+		words[i] = e.word
+	}
+	return words
+}
@@ -0,0 +1,95 @@
+package predict
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"bengali-keyboard/phonetic"
+)
+
+func identity(word string) string { return word }
+
+func TestIndexComplete(t *testing.T) {
+	words := map[string]int{
+		"ka": 5, "kaka": 9, "kaki": 9, "kal": 2, "kalo": 7, "kha": 1, "ma": 3,
+		"": 4, // left out for its empty key
+	}
+	x := NewIndex(words, identity, 0)
+	if x.Len() != len(words)-1 {
+		t.Errorf("Len() = %d, want %d", x.Len(), len(words)-1)
+	}
+
+	tests := []struct {
+		prefix string
+		limit  int
+		want   []string
+	}{
+		// Most frequent first, ties by word
+		{"ka", 0, []string{"kaka", "kaki", "kalo", "ka", "kal"}},
+		{"ka", 3, []string{"kaka", "kaki", "kalo"}},
+		{"ka", 1, []string{"kaka"}},
+		{"kal", 10, []string{"kalo", "kal"}},
+		{"k", 0, []string{"kaka", "kaki", "kalo", "ka", "kal", "kha"}},
+		{"", 2, []string{"kaka", "kaki"}},
+		{"x", 0, []string{}},
+		{"kalox", 0, []string{}},
+	}
+	for _, tt := range tests {
+		if got := x.Complete(tt.prefix, tt.limit); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q, %d) = %q, want %q", tt.prefix, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestIndexSize(t *testing.T) {
+	words := map[string]int{"a": 1, "b": 3, "c": 2, "d": 3}
+	x := NewIndex(words, identity, 2)
+	if got, want := x.Complete("", 0), []string{"b", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("index of size 2 holds %q, want the most frequent %q", got, want)
+	}
+}
+
+// benchmarkWords generates n distinct phonetic words with counts falling
+// off with rank, as in a real corpus.
+func benchmarkWords(n int) map[string]int {
+	const consonants = "bcdfghjklmnprstv"
+	const vowels = "aeiou"
+	rng := rand.New(rand.NewSource(1))
+	words := make(map[string]int, n)
+	for len(words) < n {
+		word := make([]byte, 0, 12)
+		for syllables := 1 + rng.Intn(5); syllables > 0; syllables-- {
+			word = append(word, consonants[rng.Intn(len(consonants))], vowels[rng.Intn(len(vowels))])
+		}
+		if _, ok := words[string(word)]; !ok {
+			words[string(word)] = n / (len(words) + 1)
+		}
+	}
+	return words
+}
+
+// BenchmarkComplete measures a lookup per keystroke: completing every
+// prefix of a word as it is typed.
+func BenchmarkComplete(b *testing.B) {
+	words := benchmarkWords(DefaultIndexSize)
+	x := NewIndex(words, phonetic.LatinKey, 0)
+	var typed []string
+	for word := range words {
+		typed = append(typed, word)
+		if len(typed) == 100 {
+			break
+		}
+	}
+
+	b.ResetTimer()
+	keys := 0
+	for i := 0; i < b.N; i++ {
+		word := typed[i%len(typed)]
+		for n := 1; n <= len(word); n++ {
+			x.Complete(phonetic.LatinKey(word[:n]), 5)
+		}
+		keys += len(word)
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(keys), "ns/key")
+}
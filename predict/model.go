@@ -0,0 +1,149 @@
+// Package predict suggests the next Bengali word from the words typed
+// before it, using an n-gram language model trained on a text corpus.
+package predict
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultOrder is the n-gram order of models trained without one: words
+// are predicted from the two words before them.
+const DefaultOrder = 3
+
+// Weight of a shorter context each time prediction backs off to it.
+const backoff = 0.4
+
+// Model counts how often each word follows each context of up to
+// order-1 words. It is not safe for concurrent training, but a trained
+// model can be queried concurrently.
+type Model struct {
+	order int
+
+	// Context words joined by spaces, "" for none -> next word -> count
+	counts map[string]map[string]int
+	// Total of the counts of each context
+	totals map[string]int
+}
+
+// Prediction is a predicted word with its backed-off relative frequency.
+type Prediction struct {
+	Word  string  `json:"word"`
+	Score float64 `json:"score"`
+}
+
+func NewModel(order int) *Model {
+	if order < 1 {
+		order = DefaultOrder
+	}
+	return &Model{
+		order:  order,
+		counts: make(map[string]map[string]int),
+		totals: make(map[string]int),
+	}
+}
+
+func (m *Model) Order() int {
+	return m.order
+}
+
+// AddSentence counts the n-grams of a sentence's words.
+func (m *Model) AddSentence(words []string) {
+	for i, word := range words {
+		for n := 0; n < m.order && n <= i; n++ {
+			m.add(strings.Join(words[i-n:i], " "), word, 1)
+		}
+	}
+}
+
+func (m *Model) add(context, word string, count int) {
+	next := m.counts[context]
+	if next == nil {
+		next = make(map[string]int)
+		m.counts[context] = next
+	}
+	next[word] += count
+	m.totals[context] += count
+}
+
+// Prune drops the n-grams seen fewer than minCount times, keeping every
+// word on its own.
+func (m *Model) Prune(minCount int) {
+	for context, next := range m.counts {
+		if context == "" {
+			continue
+		}
+		for word, count := range next {
+			if count < minCount {
+				delete(next, word)
+				m.totals[context] -= count
+			}
+		}
+		if len(next) == 0 {
+			delete(m.counts, context)
+			delete(m.totals, context)
+		}
+	}
+}
+
+// Predict ranks the words likely to follow history, the words typed so
+// far, oldest first. Only the words after the last sentence end count.
+// Scores use stupid backoff: the frequency after the longest known
+// context, or a shorter one scaled down. A limit of zero or less returns
+// all words seen after the contexts.
+func (m *Model) Predict(history []string, limit int) []Prediction {
+	history = sentenceContext(history)
+	if len(history) > m.order-1 {
+		history = history[len(history)-(m.order-1):]
+	}
+
+	scores := make(map[string]float64)
+	weight := 1.0
+	for n := len(history); n >= 0; n-- {
+		context := strings.Join(history[len(history)-n:], " ")
+		total := m.totals[context]
+		if total == 0 {
+			continue
+		}
+		for word, count := range m.counts[context] {
+			score := weight * float64(count) / float64(total)
+			if score > scores[word] {
+				scores[word] = score
+			}
+		}
+		weight *= backoff
+	}
+
+	predictions := make([]Prediction, 0, len(scores))
+	for word, score := range scores {
+		predictions = append(predictions, Prediction{Word: word, Score: score})
+	}
+	sort.Slice(predictions, func(i, j int) bool {
+		if predictions[i].Score != predictions[j].Score {
+			return predictions[i].Score > predictions[j].Score
+		}
+		return predictions[i].Word < predictions[j].Word
+	})
+	if limit > 0 && len(predictions) > limit {
+		predictions = predictions[:limit]
+	}
+	return predictions
+}
+
+// PredictWords is Predict without the scores.
+func (m *Model) PredictWords(history []string, limit int) []string {
+	var words []string
+	for _, p := range m.Predict(history, limit) {
+		words = append(words, p.Word)
+	}
+	return words
+}
+
+// Words returns how often the model saw each word, as used by NewIndex.
+func (m *Model) Words() map[string]int {
+	words := make(map[string]int, len(m.counts[""]))
+	for word, count := range m.counts[""] {
+		words[word] = count
+	}
+	return words
+}
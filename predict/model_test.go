@@ -0,0 +1,100 @@
+package predict
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// trainString trains a model of the given order on text.
+func trainString(t *testing.T, order int, text string) *Model {
+	t.Helper()
+	m := NewModel(order)
+	if err := m.Train(strings.NewReader(text)); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestAddSentence(t *testing.T) {
+	m := NewModel(3)
+	m.AddSentence([]string{"আমি", "ভাত", "খাই"})
+	m.AddSentence([]string{"আমি", "জল", "খাই"})
+
+	want := map[string]map[string]int{
+		"":        {"আমি": 2, "ভাত": 1, "জল": 1, "খাই": 2},
+		"আমি":     {"ভাত": 1, "জল": 1},
+		"ভাত":     {"খাই": 1},
+		"জল":      {"খাই": 1},
+		"আমি ভাত": {"খাই": 1},
+		"আমি জল":  {"খাই": 1},
+	}
+	if !reflect.DeepEqual(m.counts, want) {
+		t.Errorf("counts = %v, want %v", m.counts, want)
+	}
+	if m.totals[""] != 6 || m.totals["আমি"] != 2 || m.totals["আমি ভাত"] != 1 {
+		t.Errorf("totals = %v, want 6 for no context, 2 after আমি, 1 after আমি ভাত", m.totals)
+	}
+	if got := m.Words(); !reflect.DeepEqual(got, want[""]) {
+		t.Errorf("Words() = %v, want %v", got, want[""])
+	}
+}
+
+func TestPrune(t *testing.T) {
+	m := trainString(t, 2, "আমি ভাত খাই।\nআমি ভাত খাই।\nআমি জল খাই।")
+	m.Prune(2)
+
+	want := map[string]map[string]int{
+		"":    {"আমি": 3, "ভাত": 2, "জল": 1, "খাই": 3},
+		"আমি": {"ভাত": 2},
+		"ভাত": {"খাই": 2},
+	}
+	if !reflect.DeepEqual(m.counts, want) {
+		t.Errorf("counts after Prune(2) = %v, want %v", m.counts, want)
+	}
+	if m.totals["আমি"] != 2 {
+		t.Errorf("total after আমি = %d, want 2 without the pruned জল", m.totals["আমি"])
+	}
+	if _, ok := m.totals["জল"]; ok {
+		t.Error("emptied context জল keeps a total")
+	}
+}
+
+func TestPredictBackoff(t *testing.T) {
+	m := trainString(t, 3, "আমি ভাত খাই।\nতুমি ভাত রাঁধো। তুমি ভাত রাঁধো। তুমি ভাত রাঁধো।")
+
+	tests := []struct {
+		history []string
+		want    []string
+	}{
+		// The trigram wins over the more frequent bigram, which is scaled down
+		{[]string{"আমি", "ভাত"}, []string{"খাই", "রাঁধো", "ভাত", "তুমি", "আমি"}},
+		// An unknown context backs off without losing weight
+		{[]string{"কেউ", "ভাত"}, []string{"রাঁধো", "খাই", "ভাত", "তুমি", "আমি"}},
+		{[]string{"ভাত"}, []string{"রাঁধো", "খাই", "ভাত", "তুমি", "আমি"}},
+		// Only words after the last sentence end count
+		{[]string{"আমি", "ভাত।"}, []string{"ভাত", "তুমি", "রাঁধো", "আমি", "খাই"}},
+		{nil, []string{"ভাত", "তুমি", "রাঁধো", "আমি", "খাই"}},
+	}
+	for _, tt := range tests {
+		if got := m.PredictWords(tt.history, 0); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PredictWords(%q) = %q, want %q", tt.history, got, tt.want)
+		}
+	}
+
+	predictions := m.Predict([]string{"আমি", "ভাত"}, 2)
+	want := []Prediction{{"খাই", 1}, {"রাঁধো", backoff * 3 / 4}}
+	if len(predictions) != len(want) {
+		t.Fatalf("Predict with limit 2 = %v, want %v", predictions, want)
+	}
+	for i := range want {
+		if predictions[i].Word != want[i].Word || !almostEqual(predictions[i].Score, want[i].Score) {
+			t.Errorf("prediction %d = %v, want %v", i, predictions[i], want[i])
+		}
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	d := a - b
+	return d < 1e-9 && d > -1e-9
+}
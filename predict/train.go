@@ -0,0 +1,101 @@
+package predict
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode"
+
+	"bengali-keyboard/phonetic"
+)
+
+// Train counts the n-grams of a UTF-8 corpus into m. Sentences end at
+// দাঁড়ি, ?, ! and blank lines, and contexts never cross them. Words
+// without a Bengali letter, such as English words and numbers, break the
+// context too and are not counted.
+func (m *Model) Train(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var sentence []string
+	flush := func() {
+		if len(sentence) > 0 {
+			m.AddSentence(sentence)
+			sentence = sentence[:0]
+		}
+	}
+
+	for scanner.Scan() {
+		line := phonetic.Normalize(scanner.Text(), phonetic.NFC)
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		for _, token := range splitTokens(line) {
+			switch {
+			case token == "।" || token == "?" || token == "!":
+				flush()
+			case isBengaliWord(token):
+				sentence = append(sentence, token)
+			default:
+				flush()
+			}
+		}
+	}
+	flush()
+	return scanner.Err()
+}
+
+// sentenceContext returns the words of history after its last sentence
+// end, split and cleaned the way Train does.
+func sentenceContext(history []string) []string {
+	var words []string
+	text := phonetic.Normalize(strings.Join(history, " "), phonetic.NFC)
+	for _, token := range splitTokens(text) {
+		if isBengaliWord(token) {
+			words = append(words, token)
+		} else {
+			words = words[:0]
+		}
+	}
+	return words
+}
+
+// splitTokens splits text into words and sentence-ending punctuation.
+func splitTokens(text string) []string {
+	var tokens []string
+	start := -1
+	for i, ch := range text {
+		if isWordChar(ch) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, text[start:i])
+			start = -1
+		}
+		if ch == '।' || ch == '?' || ch == '!' {
+			tokens = append(tokens, string(ch))
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
+
+// isWordChar keeps vowel signs and joiners with their letters.
+func isWordChar(ch rune) bool {
+	return unicode.IsLetter(ch) || unicode.IsMark(ch) || unicode.IsDigit(ch) || ch == '\u200C' || ch == '\u200D'
+}
+
+func isBengaliWord(token string) bool {
+	for _, ch := range token {
+		if ch >= '\u0985' && ch <= '\u09DF' { // অ to য়
+			return true
+		}
+	}
+	return false
+}
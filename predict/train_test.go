@@ -0,0 +1,31 @@
+package predict
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrain(t *testing.T) {
+	// Sentences end at ।, ? and ! and at blank lines, not at line ends.
+	// English words and numbers break contexts without being counted.
+	m := trainString(t, 3, "আমি ভাত খাই। তুমি?\n\nসে Go লিখে, ১২ বই পড়ে!\nআমি\nভাত খাই\n\nখাই")
+
+	want := map[string]map[string]int{
+		"":        {"আমি": 2, "ভাত": 2, "খাই": 3, "তুমি": 1, "সে": 1, "লিখে": 1, "বই": 1, "পড়ে": 1},
+		"আমি":     {"ভাত": 2},
+		"ভাত":     {"খাই": 2},
+		"আমি ভাত": {"খাই": 2},
+		"বই":      {"পড়ে": 1},
+	}
+	if !reflect.DeepEqual(m.counts, want) {
+		t.Errorf("counts = %v, want %v", m.counts, want)
+	}
+}
+
+func TestTrainNormalizes(t *testing.T) {
+	// য় typed as য and nukta is the same word as the precomposed letter
+	m := trainString(t, 1, "য় য়")
+	if len(m.counts[""]) != 1 {
+		t.Errorf("words = %v, want one after normalization", m.counts[""])
+	}
+}
@@ -0,0 +1,136 @@
+//go:build !js
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"bengali-keyboard/phonetic"
+)
+
+// runREPL feeds terminal keystrokes through the same state machine as the
+// keyboard hook and prints the buffer, its conversion and the resulting
+// text after every key.
+func runREPL(args []string) error {
+	flags := flag.NewFlagSet("repl", flag.ContinueOnError)
+	live := flags.Bool("live", false, "convert after every keystroke instead of at word boundaries")
+	keymapFile := flags.String("keymap", "", "JSON keymap file to try instead of the configured keymap")
+	schemeName := flags.String("scheme", "", "scheme to type with instead of the default one")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	setupEngines()
+
+	opts := engineOptions()
+	if *schemeName != "" {
+		scheme, ok := keyboardSchemes.Lookup(*schemeName)
+		if !ok {
+			return fmt.Errorf("unknown scheme %q, available: %s", *schemeName, strings.Join(keyboardSchemes.Names(), ", "))
+		}
+		opts = append(opts, phonetic.WithKeyMap(scheme.KeyMap))
+	}
+	if *keymapFile != "" {
+		keymap, err := phonetic.LoadKeyMapFile(*keymapFile)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, phonetic.WithKeyMap(keymap))
+	}
+	if *live {
+		opts = append(opts, phonetic.WithMode(phonetic.ModeLive))
+	}
+	engine := phonetic.New(opts...)
+
+	if restore, err := makeRaw(); err == nil {
+		defer restore()
+	}
+
+	fmt.Print("Bengali Keyboard REPL - Esc or Ctrl+C to quit\r\n")
+
+	session := engine.NewSession()
+	var text []rune
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		ch, _, err := reader.ReadRune()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch ch {
+		case 0x03, 0x04, 0x1B: // Ctrl+C, Ctrl+D, Esc
+			return nil
+		case '\r':
+			ch = '\n'
+		case 0x7F:
+			ch = '\b'
+		}
+
+		edit, suppress := session.Process(ch)
+		buffer := session.Buffer()
+
+		text = applyEditToText(text, edit)
+		if !suppress {
+			text = typeIntoText(text, ch)
+		}
+
+		printREPLState(engine, ch, buffer, edit, text)
+		if session.LastWordMisspelled() {
+			history := session.History()
+			word := history[len(history)-1]
+			fmt.Printf("  misspelled %q, try: %s\r\n", word, strings.Join(engine.Corrections(word, 5), " "))
+		}
+		if completions := session.Completions(5); len(completions) > 0 {
+			fmt.Printf("  complete: %s\r\n", strings.Join(completions, " "))
+		}
+		if !edit.IsEmpty() {
+			if predictions := session.Predictions(5); len(predictions) > 0 {
+				fmt.Printf("  next: %s\r\n", strings.Join(predictions, " "))
+			}
+		}
+	}
+}
+
+func applyEditToText(text []rune, edit phonetic.Edit) []rune {
+	for i := 0; i < edit.Backspaces; i++ {
+		text = typeIntoText(text, '\b')
+	}
+	for _, ch := range edit.Text {
+		text = typeIntoText(text, ch)
+	}
+	return text
+}
+
+func typeIntoText(text []rune, ch rune) []rune {
+	if ch == '\b' {
+		if len(text) > 0 {
+			text = text[:len(text)-1]
+		}
+		return text
+	}
+	return append(text, ch)
+}
+
+func printREPLState(engine *phonetic.Engine, ch rune, buffer string, edit phonetic.Edit, text []rune) {
+	preview, matches := engine.ConvertTrace(buffer)
+
+	var rules []string
+	for _, m := range matches {
+		rules = append(rules, fmt.Sprintf("%s→%s", m.Pattern, m.Output))
+	}
+
+	fmt.Printf("key %q  buffer %q  preview %q  [%s]\r\n",
+		ch, buffer, preview, strings.Join(rules, " "))
+	if !edit.IsEmpty() {
+		fmt.Printf("  commit: %d backspace(s), %q\r\n", edit.Backspaces, edit.Text)
+	}
+	fmt.Printf("  text: %s\r\n", strings.ReplaceAll(string(text), "\n", "⏎"))
+}
@@ -0,0 +1,52 @@
+//go:build !js
+
+package main
+
+import (
+	"testing"
+
+	"bengali-keyboard/phonetic"
+)
+
+func TestTypeIntoText(t *testing.T) {
+	tests := []struct {
+		text string
+		ch   rune
+		want string
+	}{
+		{"ami", 'k', "amik"},
+		{"", 'k', "k"},
+		{"আমি", '\b', "আম"}, // one code point, not one byte
+		{"", '\b', ""},
+		{"ami", '\n', "ami\n"},
+	}
+	for _, tt := range tests {
+		if got := string(typeIntoText([]rune(tt.text), tt.ch)); got != tt.want {
+			t.Errorf("typeIntoText(%q, %q) = %q, want %q", tt.text, tt.ch, got, tt.want)
+		}
+	}
+}
+
+func TestApplyEditToText(t *testing.T) {
+	tests := []struct {
+		text string
+		edit phonetic.Edit
+		want string
+	}{
+		{"ami", phonetic.Edit{Backspaces: 3, Text: "আমি "}, "আমি "},
+		{"tumi ami", phonetic.Edit{Backspaces: 3, Text: "আমি"}, "tumi আমি"},
+		{"ami", phonetic.Edit{Text: "।"}, "ami।"},
+		{"ami", phonetic.Edit{}, "ami"},
+
+		// Backspaces past the start of the text erase what there is
+		{"k", phonetic.Edit{Backspaces: 3, Text: "ক"}, "ক"},
+
+		// Backspaces count code points of the Bengali text shown
+		{"আমি", phonetic.Edit{Backspaces: 3, Text: "আমরা"}, "আমরা"},
+	}
+	for _, tt := range tests {
+		if got := string(applyEditToText([]rune(tt.text), tt.edit)); got != tt.want {
+			t.Errorf("applyEditToText(%q, %+v) = %q, want %q", tt.text, tt.edit, got, tt.want)
+		}
+	}
+}
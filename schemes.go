@@ -0,0 +1,94 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"bengali-keyboard/phonetic"
+)
+
+// Name of the scheme read from the keymap_file setting.
+const customSchemeName = "custom"
+
+// schemeConfig is a scheme in the settings:
+//
+//	{"name": "mine", "title": "My phonetic", "description": "...", "keymap_file": "mine.json"}
+type schemeConfig struct {
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	KeymapFile  string `json:"keymap_file"`
+}
+
+// keyboardSchemes holds the built-in schemes and those of the settings,
+// once setupEngines has loaded them.
+var keyboardSchemes *phonetic.SchemeRegistry
+
+// setupEngines sets up the schemes and keyboardEngine from the settings.
+// It runs after readSettings and once logging is set up, so that errors
+// loading keymaps, dictionaries and models are logged.
+func setupEngines() {
+	keyboardSchemes = loadSchemes()
+	keyboardEngine = phonetic.New(engineOptions()...)
+}
+
+// loadSchemes registers the configured schemes and applies the default
+// and cycling order of the settings.
+func loadSchemes() *phonetic.SchemeRegistry {
+	registry := phonetic.NewSchemeRegistry(baseEngineOptions()...)
+	for _, scheme := range configuredSchemes() {
+		if err := registry.Register(scheme); err != nil {
+			slog.Error("registering scheme", "err", err)
+		}
+	}
+
+	if len(userSettings.SchemeOrder) > 0 {
+		if err := registry.SetOrder(userSettings.SchemeOrder); err != nil {
+			slog.Warn("ignoring scheme_order", "err", err)
+		}
+	}
+	def := userSettings.Scheme
+	if def == "" && userSettings.KeymapFile != "" {
+		def = customSchemeName
+	}
+	if def != "" {
+		if err := registry.SetDefault(def); err != nil {
+			slog.Warn("ignoring scheme", "err", err)
+		}
+	}
+	return registry
+}
+
+// configuredSchemes returns the built-in schemes, then the custom ones of
+// the settings. Schemes whose keymap fails to load are logged and left
+// out.
+func configuredSchemes() []phonetic.Scheme {
+	schemes := phonetic.BuiltinSchemes()
+
+	configs := userSettings.Schemes
+	if userSettings.KeymapFile != "" {
+		configs = append([]schemeConfig{{
+			Name:       customSchemeName,
+			Title:      "Custom (" + strings.TrimSuffix(filepath.Base(userSettings.KeymapFile), ".json") + ")",
+			KeymapFile: userSettings.KeymapFile,
+		}}, configs...)
+	}
+	for _, config := range configs {
+		keymap, err := phonetic.LoadKeyMapFile(config.KeymapFile)
+		if err != nil {
+			slog.Error("loading scheme", "scheme", config.Name, "err", err)
+			continue
+		}
+		// Settings only checked the triggers against the built-in schemes
+		if err := phonetic.ValidateCommitTriggers(userSettings.CommitTriggers, keymap); err != nil {
+			slog.Error("loading scheme", "scheme", config.Name, "err", fmt.Errorf("commit_triggers: %w", err))
+			continue
+		}
+		schemes = append(schemes, phonetic.Scheme{Name: config.Name, Title: config.Title, Description: config.Description, KeyMap: keymap})
+	}
+	return schemes
+}
@@ -0,0 +1,306 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+
+	"bengali-keyboard/phonetic"
+	"bengali-keyboard/predict"
+	"bengali-keyboard/spell"
+)
+
+const (
+	defaultServeAddr     = "127.0.0.1:8080"
+	defaultMaxBodyBytes  = 1 << 20
+	defaultMaxBatchItems = 1000
+	defaultPredictions   = 5
+	shutdownTimeout      = 5 * time.Second
+)
+
+// apiRequest is the body of every POST endpoint. Text holds a single input;
+// Texts makes it a batch request answered with Results in the same order.
+type apiRequest struct {
+	Keymap string   `json:"keymap,omitempty"`
+	Text   string   `json:"text,omitempty"`
+	Texts  []string `json:"texts,omitempty"`
+	Limit  int      `json:"limit,omitempty"`
+}
+
+type apiResponse struct {
+	Result  any    `json:"result,omitempty"`
+	Results []any  `json:"results,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// englishResult is the /classify answer for one word.
+type englishResult struct {
+	Score   float64 `json:"score"`
+	English bool    `json:"english"`
+}
+
+// misspelling is a word /spell flags, with its corrections.
+type misspelling struct {
+	Word        string   `json:"word"`
+	Suggestions []string `json:"suggestions"`
+}
+
+type server struct {
+	engines       map[string]*phonetic.Engine // by the keymap name requests select
+	english       *phonetic.EnglishDetector
+	spell         *spell.Dictionary
+	predictor     *predict.Model
+	completer     *predict.Index
+	metrics       bool // serve the tray keyboard's saved statistics
+	maxBodyBytes  int64
+	maxBatchItems int
+}
+
+// newServer serves conversions with engines, keyed by keymap name. The
+// endpoints needing a dictionary, a model or metrics are off until they
+// are set.
+func newServer(engines map[string]*phonetic.Engine, maxBodyBytes int64, maxBatchItems int) *server {
+	return &server{
+		engines:       engines,
+		english:       phonetic.NewEnglishDetector(phonetic.DefaultEnglishThreshold),
+		maxBodyBytes:  maxBodyBytes,
+		maxBatchItems: maxBatchItems,
+	}
+}
+
+// newKeyboardServer converts as the keyboard does with the same settings.
+func newKeyboardServer(maxBodyBytes int64, maxBatchItems int) *server {
+	engines := make(map[string]*phonetic.Engine)
+	for _, name := range keyboardSchemes.Names() {
+		scheme, _ := keyboardSchemes.Lookup(name)
+		engines[name] = scheme.Engine()
+	}
+	s := newServer(engines, maxBodyBytes, maxBatchItems)
+	s.english = userSettings.englishDetector()
+	s.spell = spellDictionary()
+	s.predictor = predictionModel()
+	s.completer = completionIndex()
+	s.metrics = userSettings.Metrics
+	return s
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/convert", s.handle(func(e *phonetic.Engine, text string, _ int) any {
+		return e.Convert(text)
+	}))
+	mux.HandleFunc("/suggest", s.handle(func(e *phonetic.Engine, text string, limit int) any {
+		return e.Suggestions(text, limit)
+	}))
+	mux.HandleFunc("/reverse", s.handle(func(e *phonetic.Engine, text string, _ int) any {
+		return e.Reverse(text)
+	}))
+	mux.HandleFunc("/classify", s.handle(func(_ *phonetic.Engine, text string, _ int) any {
+		return englishResult{Score: s.english.Score(text), English: s.english.IsEnglish(text)}
+	}))
+	mux.HandleFunc("/validate", s.handle(func(_ *phonetic.Engine, text string, _ int) any {
+		issues := []string{}
+		for _, issue := range phonetic.Validate(text) {
+			issues = append(issues, issue.String())
+		}
+		return issues
+	}))
+	mux.HandleFunc("/spell", s.handleSpell)
+	mux.HandleFunc("/predict", s.handlePredict)
+	mux.HandleFunc("/complete", s.handleComplete)
+	mux.HandleFunc("/keymaps", s.handleKeymaps)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	return mux
+}
+
+// handle decodes an apiRequest and applies fn to its text or to each text
+// of a batch.
+func (s *server) handle(fn func(e *phonetic.Engine, text string, limit int) any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, apiResponse{Error: "use POST"})
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, s.maxBodyBytes)
+
+		var req apiRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeJSON(w, http.StatusRequestEntityTooLarge, apiResponse{Error: "request body too large"})
+				return
+			}
+			writeJSON(w, http.StatusBadRequest, apiResponse{Error: "invalid JSON: " + err.Error()})
+			return
+		}
+
+		name := req.Keymap
+		if name == "" {
+			name = phonetic.DefaultKeyMapName
+		}
+		engine, ok := s.engines[name]
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, apiResponse{Error: fmt.Sprintf("unknown keymap %q", name)})
+			return
+		}
+
+		if req.Texts == nil {
+			writeJSON(w, http.StatusOK, apiResponse{Result: fn(engine, req.Text, req.Limit)})
+			return
+		}
+
+		if len(req.Texts) > s.maxBatchItems {
+			writeJSON(w, http.StatusRequestEntityTooLarge, apiResponse{
+				Error: fmt.Sprintf("batch has %d items, limit is %d", len(req.Texts), s.maxBatchItems),
+			})
+			return
+		}
+		results := make([]any, len(req.Texts))
+		for i, text := range req.Texts {
+			results[i] = fn(engine, text, req.Limit)
+		}
+		writeJSON(w, http.StatusOK, apiResponse{Results: results})
+	}
+}
+
+// handleSpell checks the Bengali words of a text and returns the
+// misspelled ones with their corrections.
+func (s *server) handleSpell(w http.ResponseWriter, r *http.Request) {
+	if s.spell == nil {
+		writeJSON(w, http.StatusNotFound, apiResponse{Error: "no spell dictionary configured"})
+		return
+	}
+	s.handle(func(e *phonetic.Engine, text string, limit int) any {
+		misspellings := []misspelling{}
+		for _, word := range strings.FieldsFunc(text, isWordSeparator) {
+			if e.Misspelled(word) {
+				suggestions := append([]string{}, e.Corrections(word, limit)...)
+				misspellings = append(misspellings, misspelling{Word: word, Suggestions: suggestions})
+			}
+		}
+		return misspellings
+	})(w, r)
+}
+
+// handlePredict ranks the words likely to follow a text, such as the
+// words committed so far.
+func (s *server) handlePredict(w http.ResponseWriter, r *http.Request) {
+	if s.predictor == nil {
+		writeJSON(w, http.StatusNotFound, apiResponse{Error: "no prediction model configured"})
+		return
+	}
+	s.handle(func(_ *phonetic.Engine, text string, limit int) any {
+		if limit <= 0 {
+			limit = defaultPredictions
+		}
+		return s.predictor.Predict(strings.Fields(text), limit)
+	})(w, r)
+}
+
+// handleComplete lists the words a partly typed Latin word may complete
+// to, most frequent first.
+func (s *server) handleComplete(w http.ResponseWriter, r *http.Request) {
+	if s.completer == nil {
+		writeJSON(w, http.StatusNotFound, apiResponse{Error: "completion is not enabled"})
+		return
+	}
+	s.handle(func(_ *phonetic.Engine, text string, limit int) any {
+		if limit <= 0 {
+			limit = defaultPredictions
+		}
+		key := phonetic.LatinKey(text)
+		if key == "" {
+			return []string{}
+		}
+		return s.completer.Complete(key, limit)
+	})(w, r)
+}
+
+// isWordSeparator splits text into words, keeping vowel signs and joiners
+// with their letters.
+func isWordSeparator(ch rune) bool {
+	return !unicode.IsLetter(ch) && !unicode.IsMark(ch) && ch != '\u200C' && ch != '\u200D'
+}
+
+func (s *server) handleKeymaps(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(s.engines))
+	for name := range s.engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	writeJSON(w, http.StatusOK, apiResponse{Result: names})
+}
+
+// handleMetrics exposes the tray keyboard's saved statistics to Prometheus.
+func (s *server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !s.metrics {
+		http.Error(w, "metrics are disabled in settings", http.StatusNotFound)
+		return
+	}
+	snapshot, err := loadMetricsSnapshot()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writePrometheus(w, snapshot)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// runServe serves the conversion engine over HTTP until interrupted, then
+// lets in-flight requests finish before exiting.
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", defaultServeAddr, "address to listen on")
+	maxBodyBytes := flags.Int64("max-body", defaultMaxBodyBytes, "maximum request body size in bytes")
+	maxBatchItems := flags.Int("max-batch", defaultMaxBatchItems, "maximum number of texts in a batch request")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	setupEngines()
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           newKeyboardServer(*maxBodyBytes, *maxBatchItems).handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		fmt.Printf("Serving on http://%s\n", *addr)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	fmt.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
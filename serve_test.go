@@ -0,0 +1,202 @@
+//go:build !js
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"bengali-keyboard/phonetic"
+	"bengali-keyboard/predict"
+	"bengali-keyboard/spell"
+)
+
+// newTestServer serves the built-in keymaps with engines of their own,
+// leaving the settings and files of whoever runs the tests alone.
+func newTestServer(maxBodyBytes int64, maxBatchItems int, opts ...phonetic.Option) *server {
+	engines := make(map[string]*phonetic.Engine)
+	for _, scheme := range phonetic.BuiltinSchemes() {
+		engines[scheme.Name] = phonetic.New(append(opts, phonetic.WithKeyMap(scheme.KeyMap))...)
+	}
+	return newServer(engines, maxBodyBytes, maxBatchItems)
+}
+
+func post(t *testing.T, handler http.Handler, path, body string) (int, apiResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var resp apiResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("POST %s %s: response %q is not JSON: %v", path, body, rec.Body, err)
+	}
+	return rec.Code, resp
+}
+
+func TestServeConvert(t *testing.T) {
+	handler := newTestServer(defaultMaxBodyBytes, defaultMaxBatchItems).handler()
+
+	code, resp := post(t, handler, "/convert", `{"text": "ami"}`)
+	if code != http.StatusOK || resp.Result != "আমি" {
+		t.Errorf("single: got %d %+v, want 200 আমি", code, resp)
+	}
+
+	code, resp = post(t, handler, "/convert", `{"texts": ["ami", "tumi", ""], "keymap": "phonetic"}`)
+	want := []any{"আমি", "তুমি", ""}
+	if code != http.StatusOK || len(resp.Results) != len(want) {
+		t.Fatalf("batch: got %d %+v, want 200 %v", code, resp, want)
+	}
+	for i := range want {
+		if resp.Results[i] != want[i] {
+			t.Errorf("batch result %d = %v, want %v", i, resp.Results[i], want[i])
+		}
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	handler := newTestServer(64, 2).handler()
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"unknown keymap", `{"text": "ami", "keymap": "nope"}`, http.StatusBadRequest},
+		{"invalid JSON", `{"text": `, http.StatusBadRequest},
+		{"body over limit", `{"text": "` + strings.Repeat("a", 100) + `"}`, http.StatusRequestEntityTooLarge},
+		{"batch over limit", `{"texts": ["a", "b", "c"]}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		code, resp := post(t, handler, "/convert", tt.body)
+		if code != tt.code || resp.Error == "" {
+			t.Errorf("%s: got %d %+v, want %d with an error", tt.name, code, resp, tt.code)
+		}
+	}
+}
+
+func TestServeMethodNotAllowed(t *testing.T) {
+	handler := newTestServer(defaultMaxBodyBytes, defaultMaxBatchItems).handler()
+
+	req := httptest.NewRequest(http.MethodGet, "/convert", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /convert: got %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if allow := rec.Header().Get("Allow"); allow != http.MethodPost {
+		t.Errorf("Allow header = %q, want %q", allow, http.MethodPost)
+	}
+}
+
+func TestServeSuggest(t *testing.T) {
+	handler := newTestServer(defaultMaxBodyBytes, defaultMaxBatchItems).handler()
+
+	code, resp := post(t, handler, "/suggest", `{"text": "ami", "limit": 2}`)
+	if want := []any{"আমি", "আমী"}; code != http.StatusOK || !reflect.DeepEqual(resp.Result, want) {
+		t.Errorf("single: got %d %+v, want 200 %v", code, resp, want)
+	}
+
+	code, resp = post(t, handler, "/suggest", `{"texts": ["ami", "kal"], "limit": 1}`)
+	if want := []any{[]any{"আমি"}, []any{"কাল"}}; code != http.StatusOK || !reflect.DeepEqual(resp.Results, want) {
+		t.Errorf("batch: got %d %+v, want 200 %v", code, resp, want)
+	}
+}
+
+func TestServeReverse(t *testing.T) {
+	handler := newTestServer(defaultMaxBodyBytes, defaultMaxBatchItems).handler()
+
+	tests := []struct {
+		body string
+		want any
+	}{
+		{`{"text": "আমি"}`, "ami"},
+		{`{"text": "বাংলা", "keymap": "phonetic"}`, "bangla"},
+		{`{"text": "ক্স", "keymap": "phonetic"}`, "ks"},
+		{`{"text": "ক্স", "keymap": "avro"}`, "x"},
+	}
+	for _, tt := range tests {
+		code, resp := post(t, handler, "/reverse", tt.body)
+		if code != http.StatusOK || resp.Result != tt.want {
+			t.Errorf("%s: got %d %+v, want 200 %v", tt.body, code, resp, tt.want)
+		}
+	}
+}
+
+func TestServeKeymaps(t *testing.T) {
+	handler := newTestServer(defaultMaxBodyBytes, defaultMaxBatchItems).handler()
+
+	req := httptest.NewRequest(http.MethodGet, "/keymaps", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var resp apiResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if want := []any{"avro", "phonetic"}; rec.Code != http.StatusOK || !reflect.DeepEqual(resp.Result, want) {
+		t.Errorf("GET /keymaps: got %d %+v, want 200 %v", rec.Code, resp, want)
+	}
+}
+
+func TestServeUnconfigured(t *testing.T) {
+	handler := newTestServer(defaultMaxBodyBytes, defaultMaxBatchItems).handler()
+
+	for _, path := range []string{"/spell", "/predict", "/complete"} {
+		code, resp := post(t, handler, path, `{"text": "ami"}`)
+		if code != http.StatusNotFound || resp.Error == "" {
+			t.Errorf("%s without its data: got %d %+v, want 404 with an error", path, code, resp)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /metrics with metrics off: got %d, want 404", rec.Code)
+	}
+}
+
+func TestServeConfigured(t *testing.T) {
+	dictionary, err := spell.Parse(strings.NewReader(""), strings.NewReader("আমি\nকারণ\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := predict.NewModel(2)
+	model.AddSentence([]string{"আমি", "ভাত", "খাই"})
+	model.AddSentence([]string{"আমি", "ভাত", "খাই"})
+	model.AddSentence([]string{"আমি", "জল", "খাই"})
+
+	s := newTestServer(defaultMaxBodyBytes, defaultMaxBatchItems, phonetic.WithSpellChecker(dictionary))
+	s.spell = dictionary
+	s.predictor = model
+	s.completer = predict.NewIndex(model.Words(), phonetic.New().CompletionKey, 0)
+	handler := s.handler()
+
+	tests := []struct {
+		path string
+		body string
+		want string // JSON of the result
+	}{
+		{"/spell", `{"text": "আমি কারন বলি"}`, `[{"word":"কারন","suggestions":["কারণ"]},{"word":"বলি","suggestions":[]}]`},
+		{"/spell", `{"text": "আমি কারণ"}`, `[]`},
+		{"/predict", `{"text": "আমি", "limit": 1}`, `[{"word":"ভাত","score":0.6666666666666666}]`},
+		{"/complete", `{"text": "bh"}`, `["ভাত"]`},
+		{"/complete", `{"text": ""}`, `[]`},
+	}
+	for _, tt := range tests {
+		var want any
+		if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+			t.Fatal(err)
+		}
+		code, resp := post(t, handler, tt.path, tt.body)
+		if code != http.StatusOK || !reflect.DeepEqual(resp.Result, want) {
+			t.Errorf("%s %s: got %d %+v, want 200 %s", tt.path, tt.body, code, resp, tt.want)
+		}
+	}
+}
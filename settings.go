@@ -0,0 +1,256 @@
+//go:build !js
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"bengali-keyboard/phonetic"
+	"bengali-keyboard/predict"
+	"bengali-keyboard/spell"
+)
+
+// Settings are read from settings.json in the configuration directory.
+// Missing fields keep their defaults.
+type Settings struct {
+	// Leave words that look English unconverted
+	DetectEnglish     bool     `json:"detect_english"`
+	EnglishThreshold  float64  `json:"english_threshold,omitempty"`
+	EnglishExceptions []string `json:"english_exceptions,omitempty"` // always converted
+
+	// Replace words with their preferred spelling on commit
+	Autocorrect         bool              `json:"autocorrect"`
+	AutocorrectWords    map[string]string `json:"autocorrect_words,omitempty"`    // added to the built-in list
+	AutocorrectDisabled []string          `json:"autocorrect_disabled,omitempty"` // words left as converted
+
+	DigitMode        string `json:"digit_mode,omitempty"` // bengali, ascii or context
+	NumberFormatting bool   `json:"number_formatting"`    // group digits in lakhs and crores
+
+	Normalization string `json:"normalization,omitempty"` // nfc, nfd or none
+
+	// Characters besides space, tab and newline that end a word, instead
+	// of phonetic.DefaultCommitTriggers
+	CommitTriggers string `json:"commit_triggers,omitempty"`
+
+	KeymapFile string `json:"keymap_file,omitempty"` // JSON keymap of a "custom" scheme typing starts with
+
+	// Schemes switched between with F8 or the tray menu
+	Scheme      string         `json:"scheme,omitempty"`       // scheme typing starts with
+	SchemeOrder []string       `json:"scheme_order,omitempty"` // schemes F8 cycles through
+	Schemes     []schemeConfig `json:"schemes,omitempty"`      // schemes read from keymap files
+
+	DateFormat string `json:"date_format,omitempty"` // date inserted by F9, see phonetic.FormatDate
+
+	// Hunspell dictionary, e.g. dicts/bn_BD for bn_BD.aff and bn_BD.dic
+	SpellDictionary string `json:"spell_dictionary,omitempty"`
+
+	PredictionModel string `json:"prediction_model,omitempty"` // n-gram model made with the train command
+	Completion      bool   `json:"completion"`                 // complete long words from the model's words
+	CompletionWords int    `json:"completion_words,omitempty"` // most frequent words indexed, see predict.NewIndex
+
+	// Count conversions locally in stats.json; no typed text is stored
+	Metrics bool `json:"metrics"`
+
+	LogLevel   string `json:"log_level,omitempty"` // debug, info, warn or error
+	LogContent bool   `json:"log_content"`         // log typed text instead of its length
+}
+
+// userSettings are the settings read by readSettings, or the defaults
+// before.
+var userSettings Settings
+
+// spellDictionary loads the dictionary of the settings when first called,
+// so only commands checking spelling pay for it. It is nil unless one is
+// set.
+var spellDictionary = sync.OnceValue(func() *spell.Dictionary {
+	return readSpellDictionary(userSettings.SpellDictionary)
+})
+
+// predictionModel loads the model of the settings when first called, nil
+// unless one is set.
+var predictionModel = sync.OnceValue(func() *predict.Model {
+	return readPredictionModel(userSettings.PredictionModel)
+})
+
+// completionIndex indexes the words of the prediction model when first
+// called. It is nil unless completion is on and a model is set.
+var completionIndex = sync.OnceValue(buildCompletionIndex)
+
+// configDir is where settings and other per-user files live.
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bengali-keyboard"), nil
+}
+
+func loadSettings() (Settings, error) {
+	var settings Settings
+
+	dir, err := configDir()
+	if err != nil {
+		return settings, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "settings.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, err
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("settings.json: %w", err)
+	}
+	if _, ok := digitModes[settings.DigitMode]; !ok {
+		return settings, fmt.Errorf("settings.json: unknown digit_mode %q", settings.DigitMode)
+	}
+	if _, ok := normForms[settings.Normalization]; !ok {
+		return settings, fmt.Errorf("settings.json: unknown normalization %q", settings.Normalization)
+	}
+	for _, scheme := range phonetic.BuiltinSchemes() {
+		if err := phonetic.ValidateCommitTriggers(settings.CommitTriggers, scheme.KeyMap); err != nil {
+			return settings, fmt.Errorf("settings.json: commit_triggers: %w", err)
+		}
+	}
+	if err := phonetic.ValidateDatePattern(settings.DateFormat); err != nil {
+		return settings, fmt.Errorf("settings.json: date_format: %w", err)
+	}
+	if _, ok := logLevels[settings.LogLevel]; !ok {
+		return settings, fmt.Errorf("settings.json: unknown log_level %q", settings.LogLevel)
+	}
+	return settings, nil
+}
+
+// readSettings sets userSettings with loadSettings. Its error is only
+// returned, since logging is set up from the settings and can report it
+// afterwards; a broken file does not keep the keyboard from starting.
+func readSettings() error {
+	settings, err := loadSettings()
+	userSettings = settings
+	return err
+}
+
+// readSpellDictionary loads the Hunspell dictionary at path, given with or
+// without the .aff or .dic extension. Errors are reported and leave spell
+// checking off.
+func readSpellDictionary(path string) *spell.Dictionary {
+	if path == "" {
+		return nil
+	}
+	base := strings.TrimSuffix(strings.TrimSuffix(path, ".aff"), ".dic")
+	dictionary, err := spell.Load(base+".aff", base+".dic")
+	if err != nil {
+		slog.Error("loading spell dictionary", "err", err)
+		return nil
+	}
+	return dictionary
+}
+
+// autocorrect returns the built-in autocorrect table with the user's
+// additions and without the words they disabled.
+func (s Settings) autocorrect() phonetic.Autocorrect {
+	table := phonetic.DefaultAutocorrect()
+	for word, preferred := range s.AutocorrectWords {
+		table[word] = preferred
+	}
+	for _, word := range s.AutocorrectDisabled {
+		delete(table, word)
+	}
+	return table
+}
+
+func readPredictionModel(path string) *predict.Model {
+	if path == "" {
+		return nil
+	}
+	model, err := predict.Load(path)
+	if err != nil {
+		slog.Error("loading prediction model", "err", err)
+		return nil
+	}
+	return model
+}
+
+func buildCompletionIndex() *predict.Index {
+	if !userSettings.Completion {
+		return nil
+	}
+	model := predictionModel()
+	if model == nil {
+		return nil
+	}
+	// Keys hardly depend on the scheme, so one index serves them all
+	engine := phonetic.New()
+	return predict.NewIndex(model.Words(), engine.CompletionKey, userSettings.CompletionWords)
+}
+
+func (s Settings) dateFormat() string {
+	if s.DateFormat == "" {
+		return "{bangla_date}"
+	}
+	return s.DateFormat
+}
+
+func (s Settings) englishDetector() *phonetic.EnglishDetector {
+	threshold := s.EnglishThreshold
+	if threshold == 0 {
+		threshold = phonetic.DefaultEnglishThreshold
+	}
+	detector := phonetic.NewEnglishDetector(threshold)
+	for _, word := range s.EnglishExceptions {
+		detector.AddException(word)
+	}
+	return detector
+}
+
+// engineOptions configures an engine for the default scheme.
+func engineOptions() []phonetic.Option {
+	return append(baseEngineOptions(), phonetic.WithKeyMap(keyboardSchemes.Default().KeyMap))
+}
+
+// baseEngineOptions configures an engine for any scheme.
+func baseEngineOptions() []phonetic.Option {
+	opts := []phonetic.Option{
+		phonetic.WithDigitMode(digitModes[userSettings.DigitMode]),
+		phonetic.WithNumberFormatting(userSettings.NumberFormatting),
+		phonetic.WithNormalization(normForms[userSettings.Normalization]),
+	}
+	if userSettings.CommitTriggers != "" {
+		opts = append(opts, phonetic.WithCommitTriggers(userSettings.CommitTriggers))
+	}
+	if userSettings.DetectEnglish {
+		opts = append(opts, phonetic.WithEnglishDetector(userSettings.englishDetector()))
+	}
+	if macros, err := loadMacros(); err != nil {
+		slog.Error("loading macros", "err", err)
+	} else if len(macros) > 0 {
+		opts = append(opts, phonetic.WithMacros(macros))
+	}
+	if userSettings.Autocorrect {
+		opts = append(opts, phonetic.WithAutocorrect(userSettings.autocorrect()))
+	}
+	if dictionary := spellDictionary(); dictionary != nil {
+		opts = append(opts, phonetic.WithSpellChecker(dictionary))
+	}
+	if model := predictionModel(); model != nil {
+		opts = append(opts, phonetic.WithPredictor(model))
+	}
+	if index := completionIndex(); index != nil {
+		opts = append(opts, phonetic.WithCompleter(index))
+	}
+	if keyboardMetrics != nil {
+		opts = append(opts, phonetic.WithMetrics(keyboardMetrics))
+	}
+	return opts
+}
@@ -0,0 +1,402 @@
+// Package spell checks Bengali words against Hunspell dictionaries, such as
+// bn_BD.aff and bn_BD.dic, and suggests corrections for misspelled ones.
+//
+// It reads the parts of the affix file that matter for Bengali: SET, FLAG,
+// TRY, REP, PFX, SFX, NEEDAFFIX and FORBIDDENWORD. Compounding and
+// continuation classes are not supported.
+package spell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"bengali-keyboard/phonetic"
+)
+
+// Dictionary is a word list with the affix rules that inflect its words.
+// It is safe for concurrent use once loaded.
+type Dictionary struct {
+	words map[string]flagSet
+
+	// Affixes indexed by the text they add
+	prefixes map[string][]*affix
+	suffixes map[string][]*affix
+
+	try []rune      // characters tried when suggesting
+	rep [][2]string // common misspellings, from REP
+
+	flagMode      string // "", "UTF-8", "long" or "num"
+	needAffix     flag
+	forbiddenWord flag
+}
+
+type flag string
+
+type flagSet map[flag]bool
+
+// affix is one PFX or SFX rule: strip is removed from the stem and add
+// put in its place, where the stem matches condition.
+type affix struct {
+	flag      flag
+	cross     bool // combines with affixes of the other kind
+	strip     string
+	add       string
+	condition condition
+}
+
+// condition is the pattern a stem must match at the affixed end, one
+// element per character.
+type condition []conditionChar
+
+type conditionChar struct {
+	any    bool
+	negate bool
+	chars  map[rune]bool
+}
+
+// Load reads a dictionary from its .aff and .dic files.
+func Load(affPath, dicPath string) (*Dictionary, error) {
+	aff, err := os.Open(affPath)
+	if err != nil {
+		return nil, err
+	}
+	defer aff.Close()
+
+	dic, err := os.Open(dicPath)
+	if err != nil {
+		return nil, err
+	}
+	defer dic.Close()
+
+	d, err := Parse(aff, dic)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", strings.TrimSuffix(dicPath, ".dic"), err)
+	}
+	return d, nil
+}
+
+// Parse reads a dictionary in Hunspell format. Both files must be UTF-8.
+func Parse(aff, dic io.Reader) (*Dictionary, error) {
+	d := &Dictionary{
+		words:    make(map[string]flagSet),
+		prefixes: make(map[string][]*affix),
+		suffixes: make(map[string][]*affix),
+	}
+	if err := d.parseAff(aff); err != nil {
+		return nil, fmt.Errorf("aff: %w", err)
+	}
+	if err := d.parseDic(dic); err != nil {
+		return nil, fmt.Errorf("dic: %w", err)
+	}
+	return d, nil
+}
+
+func (d *Dictionary) parseAff(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	line := 0
+
+	// Lines still expected for the affix class being read
+	var pendingKind string
+	var pendingCross bool
+	pendingCount := 0
+
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(stripComment(scanner.Text()))
+		if len(fields) == 0 {
+			continue
+		}
+
+		if pendingCount > 0 && fields[0] == pendingKind {
+			if len(fields) < 4 {
+				return fmt.Errorf("line %d: short %s rule", line, pendingKind)
+			}
+			a, err := d.parseAffix(fields, pendingCross)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			if pendingKind == "PFX" {
+				d.prefixes[a.add] = append(d.prefixes[a.add], a)
+			} else {
+				d.suffixes[a.add] = append(d.suffixes[a.add], a)
+			}
+			pendingCount--
+			continue
+		}
+
+		switch fields[0] {
+		case "SET":
+			if len(fields) > 1 && !strings.EqualFold(fields[1], "UTF-8") {
+				return fmt.Errorf("line %d: encoding %s is not supported, convert the dictionary to UTF-8", line, fields[1])
+			}
+		case "FLAG":
+			if len(fields) > 1 {
+				d.flagMode = fields[1]
+			}
+		case "TRY":
+			if len(fields) > 1 {
+				d.try = []rune(normalize(fields[1]))
+			}
+		case "NEEDAFFIX":
+			if len(fields) > 1 {
+				d.needAffix = flag(fields[1])
+			}
+		case "FORBIDDENWORD":
+			if len(fields) > 1 {
+				d.forbiddenWord = flag(fields[1])
+			}
+		case "REP":
+			// The first REP line holds the count
+			if len(fields) == 3 {
+				d.rep = append(d.rep, [2]string{
+					normalize(strings.ReplaceAll(fields[1], "_", " ")),
+					normalize(strings.ReplaceAll(fields[2], "_", " ")),
+				})
+			}
+		case "PFX", "SFX":
+			// Header: PFX flag cross_product count
+			if len(fields) < 4 {
+				return fmt.Errorf("line %d: short %s header", line, fields[0])
+			}
+			count, err := strconv.Atoi(fields[3])
+			if err != nil {
+				return fmt.Errorf("line %d: bad %s count %q", line, fields[0], fields[3])
+			}
+			pendingKind = fields[0]
+			pendingCross = fields[2] == "Y"
+			pendingCount = count
+		}
+	}
+	return scanner.Err()
+}
+
+// parseAffix reads a rule line: PFX flag strip add[/flags] [condition].
+func (d *Dictionary) parseAffix(fields []string, cross bool) (*affix, error) {
+	a := &affix{flag: flag(fields[1]), cross: cross}
+
+	if fields[2] != "0" {
+		a.strip = normalize(fields[2])
+	}
+	add, _, _ := strings.Cut(fields[3], "/") // continuation classes are ignored
+	if add != "0" {
+		a.add = normalize(add)
+	}
+
+	pattern := "."
+	if len(fields) > 4 {
+		pattern = fields[4]
+	}
+	cond, err := parseCondition(normalize(pattern))
+	if err != nil {
+		return nil, err
+	}
+	a.condition = cond
+	return a, nil
+}
+
+func parseCondition(pattern string) (condition, error) {
+	var cond condition
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '.':
+			cond = append(cond, conditionChar{any: true})
+		case '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unclosed [ in condition %q", pattern)
+			}
+			c := conditionChar{chars: make(map[rune]bool)}
+			class := runes[i+1 : end]
+			if len(class) > 0 && class[0] == '^' {
+				c.negate = true
+				class = class[1:]
+			}
+			for _, ch := range class {
+				c.chars[ch] = true
+			}
+			cond = append(cond, c)
+			i = end
+		default:
+			cond = append(cond, conditionChar{chars: map[rune]bool{runes[i]: true}})
+		}
+	}
+	return cond, nil
+}
+
+func (c conditionChar) matches(ch rune) bool {
+	return c.any || c.chars[ch] != c.negate
+}
+
+// matchesStart reports whether the condition matches the start of stem.
+func (c condition) matchesStart(stem []rune) bool {
+	if len(stem) < len(c) {
+		return false
+	}
+	for i, cc := range c {
+		if !cc.matches(stem[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesEnd reports whether the condition matches the end of stem.
+func (c condition) matchesEnd(stem []rune) bool {
+	if len(stem) < len(c) {
+		return false
+	}
+	offset := len(stem) - len(c)
+	for i, cc := range c {
+		if !cc.matches(stem[offset+i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *Dictionary) parseDic(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	first := true
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if first {
+			// The first line holds the approximate word count
+			first = false
+			if _, err := strconv.Atoi(text); err == nil {
+				continue
+			}
+		}
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		// word/flags, optionally followed by morphological fields
+		entry := strings.Fields(text)[0]
+		word, flags, _ := strings.Cut(entry, "/")
+		word = normalize(word)
+		set := d.words[word]
+		if set == nil {
+			set = make(flagSet)
+			d.words[word] = set
+		}
+		for _, f := range d.splitFlags(flags) {
+			set[f] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(d.words) == 0 {
+		return errors.New("no words")
+	}
+	return nil
+}
+
+// splitFlags splits a flag string according to the FLAG setting.
+func (d *Dictionary) splitFlags(flags string) []flag {
+	var result []flag
+	switch d.flagMode {
+	case "long":
+		runes := []rune(flags)
+		for i := 0; i+1 < len(runes); i += 2 {
+			result = append(result, flag(runes[i:i+2]))
+		}
+	case "num":
+		for _, f := range strings.Split(flags, ",") {
+			if f != "" {
+				result = append(result, flag(f))
+			}
+		}
+	default:
+		for _, ch := range flags {
+			result = append(result, flag(ch))
+		}
+	}
+	return result
+}
+
+// Check reports whether word is spelled correctly: a dictionary word, or
+// one with a prefix, a suffix or both that the dictionary allows.
+func (d *Dictionary) Check(word string) bool {
+	word = normalize(word)
+	if word == "" {
+		return false
+	}
+	if flags, ok := d.words[word]; ok {
+		if flags[d.forbiddenWord] {
+			return false
+		}
+		if !flags[d.needAffix] {
+			return true
+		}
+	}
+	if d.checkSuffixed(word, "") {
+		return true
+	}
+
+	runes := []rune(word)
+	for n := 1; n <= len(runes); n++ {
+		for _, a := range d.prefixes[string(runes[:n])] {
+			stem := a.strip + string(runes[n:])
+			if !a.condition.matchesStart([]rune(stem)) {
+				continue
+			}
+			if d.hasFlag(stem, a.flag) {
+				return true
+			}
+			if a.cross && d.checkSuffixed(stem, a.flag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkSuffixed reports whether word is a stem with one of its suffixes.
+// With a prefix flag, the stem must also allow that prefix and the suffix
+// must combine with prefixes.
+func (d *Dictionary) checkSuffixed(word string, prefixFlag flag) bool {
+	runes := []rune(word)
+	for n := 0; n <= len(runes); n++ {
+		for _, a := range d.suffixes[string(runes[n:])] {
+			if prefixFlag != "" && !a.cross {
+				continue
+			}
+			stem := string(runes[:n]) + a.strip
+			if !a.condition.matchesEnd([]rune(stem)) || !d.hasFlag(stem, a.flag) {
+				continue
+			}
+			if prefixFlag == "" || d.hasFlag(stem, prefixFlag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (d *Dictionary) hasFlag(stem string, f flag) bool {
+	flags, ok := d.words[stem]
+	return ok && flags[f] && !flags[d.forbiddenWord]
+}
+
+func stripComment(line string) string {
+	if strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return ""
+	}
+	return line
+}
+
+// normalize puts text in the form the keyboard types, so decomposed
+// vowel signs in a dictionary still match.
+func normalize(text string) string {
+	return phonetic.Normalize(text, phonetic.NFC)
+}
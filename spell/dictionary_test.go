@@ -0,0 +1,190 @@
+package spell
+
+import (
+	"strings"
+	"testing"
+)
+
+// testAff and testDic make a small dictionary using every rule kind the
+// package reads.
+const testAff = `SET UTF-8
+# Letters for suggestions; ণ only comes from REP
+TRY কখগমলা
+FORBIDDENWORD !
+NEEDAFFIX *
+
+REP 1
+REP ন ণ
+
+SFX A Y 2
+SFX A 0 টি .
+SFX A 0 য় [াে]
+
+SFX C N 1
+SFX C 0 ি .
+
+PFX B Y 1
+PFX B 0 অ [^অ]
+
+PFX D N 1
+PFX D পা বে পা
+`
+
+const testDic = `8
+বই/AB
+পাতা/A
+কর/*C
+কারণ
+কারন/A!
+পার/D
+কলম
+কম
+`
+
+func parseTestDictionary(t *testing.T, aff, dic string) *Dictionary {
+	t.Helper()
+	d, err := Parse(strings.NewReader(aff), strings.NewReader(dic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestCheck(t *testing.T) {
+	d := parseTestDictionary(t, testAff, testDic)
+
+	tests := []struct {
+		word string
+		want bool
+	}{
+		{"বই", true},
+		{"কারণ", true},
+		{"", false},
+		{"বইখাতা", false},
+
+		// SFX without a condition
+		{"বইটি", true},
+		{"পাতাটি", true},
+		{"কলমটি", false}, // stem without the flag
+
+		// SFX condition on the stem's last character
+		{"পাতায়", true},
+		{"বইয়", false},
+
+		// PFX with a negated condition, and crossed with a suffix
+		{"অবই", true},
+		{"অবইটি", true},
+		{"অঅবই", false},
+		{"অপাতা", false}, // stem without the flag
+
+		// PFX stripping পা from the stem and adding বে in its place
+		{"বের", true},
+		{"পার", true},
+		{"বেপার", false},
+
+		// NEEDAFFIX: the stem alone is no word, its affixed forms are
+		{"কর", false},
+		{"করি", true},
+
+		// FORBIDDENWORD, also for the stem's affixed forms
+		{"কারন", false},
+		{"কারনটি", false},
+	}
+	for _, tt := range tests {
+		if got := d.Check(tt.word); got != tt.want {
+			t.Errorf("Check(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestCheckNoCross(t *testing.T) {
+	// C is not crossable, so a prefix cannot combine with it
+	d := parseTestDictionary(t, testAff+"PFX E N 1\nPFX E 0 অ .\n", "কর/*CE\n")
+	for word, want := range map[string]bool{"করি": true, "অকর": true, "অকরি": false} {
+		if got := d.Check(word); got != want {
+			t.Errorf("Check(%q) = %v, want %v", word, got, want)
+		}
+	}
+}
+
+func TestFlagModes(t *testing.T) {
+	tests := []struct {
+		name string
+		aff  string
+		dic  string
+	}{
+		{
+			"single character",
+			"SFX A Y 1\nSFX A 0 টি .\nPFX B Y 1\nPFX B 0 অ .\n",
+			"বই/AB\nকলম/B\n",
+		},
+		{
+			"long",
+			"FLAG long\nSFX Aa Y 1\nSFX Aa 0 টি .\nPFX Bb Y 1\nPFX Bb 0 অ .\n",
+			"বই/AaBb\nকলম/BbAx\n",
+		},
+		{
+			"num",
+			"FLAG num\nSFX 101 Y 1\nSFX 101 0 টি .\nPFX 7 Y 1\nPFX 7 0 অ .\n",
+			"বই/7,101\nকলম/7,10\n",
+		},
+	}
+	for _, tt := range tests {
+		d := parseTestDictionary(t, tt.aff, tt.dic)
+		for word, want := range map[string]bool{
+			"বইটি":   true,
+			"অবইটি":  true,
+			"অকলম":   true,
+			"কলমটি":  false,
+			"অকলমটি": false,
+		} {
+			if got := d.Check(word); got != want {
+				t.Errorf("%s flags: Check(%q) = %v, want %v", tt.name, word, got, want)
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	d := parseTestDictionary(t, testAff, testDic)
+	if len(d.words) != 8 {
+		t.Errorf("read %d words, want 8", len(d.words))
+	}
+	if len(d.suffixes["টি"]) != 1 || len(d.suffixes[normalize("য়")]) != 1 || len(d.prefixes["অ"]) != 1 {
+		t.Errorf("affixes not indexed by what they add: suffixes %v, prefixes %v", d.suffixes, d.prefixes)
+	}
+	if len(d.rep) != 1 || d.rep[0] != [2]string{"ন", "ণ"} {
+		t.Errorf("REP = %q, want [[ন ণ]]", d.rep)
+	}
+	if string(d.try) != "কখগমলা" {
+		t.Errorf("TRY = %q, want কখগমলা", string(d.try))
+	}
+
+	// Without a count line every line is a word
+	d = parseTestDictionary(t, "", "বই\nকলম\n# comment\n\n")
+	if len(d.words) != 2 {
+		t.Errorf("read %d words without a count line, want 2", len(d.words))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		aff  string
+		dic  string
+		want string
+	}{
+		{"encoding", "SET ISO8859-1\n", "বই\n", "aff: line 1: encoding ISO8859-1 is not supported"},
+		{"short header", "SFX A Y\n", "বই\n", "aff: line 1: short SFX header"},
+		{"bad count", "PFX A Y many\n", "বই\n", `aff: line 1: bad PFX count "many"`},
+		{"short rule", "SFX A Y 1\nSFX A 0\n", "বই\n", "aff: line 2: short SFX rule"},
+		{"unclosed class", "SFX A Y 1\nSFX A 0 টি [াে\n", "বই\n", "aff: line 2: unclosed [ in condition"},
+		{"no words", "", "0\n", "dic: no words"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.aff), strings.NewReader(tt.dic))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v, want one containing %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package spell
+
+import (
+	"sort"
+	"strings"
+
+	"bengali-keyboard/phonetic"
+)
+
+// Letters tried for insertions and replacements when the affix file has
+// no TRY line: the Bengali letters and signs.
+var defaultTry = []rune("অআইঈউঊঋএঐওঔকখগঘঙচছজঝঞটঠডঢণতথদধনপফবভমযরলশষসহড়ঢ়য়ৎংঃঁািীুূৃেৈোৌ্")
+
+// Suggest returns up to limit correctly spelled words one edit away from
+// word, or one REP replacement away, closest first. distance ranks them;
+// phonetic.Engine.TypingDistance ranks by the keys typed, so a candidate
+// that differs in a letter typed with the same key comes first. A nil
+// distance counts edited characters. A limit of zero or less returns all.
+func (d *Dictionary) Suggest(word string, limit int, distance func(a, b string) int) []string {
+	word = normalize(word)
+	if distance == nil {
+		distance = phonetic.EditDistance
+	}
+
+	seen := map[string]bool{word: true}
+	var candidates []string
+	try := func(candidate string) {
+		if !seen[candidate] {
+			seen[candidate] = true
+			if d.Check(candidate) {
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+
+	for _, rep := range d.rep {
+		for offset := 0; ; {
+			idx := strings.Index(word[offset:], rep[0])
+			if idx < 0 {
+				break
+			}
+			idx += offset
+			try(word[:idx] + rep[1] + word[idx+len(rep[0]):])
+			offset = idx + len(rep[0])
+		}
+	}
+
+	letters := d.try
+	if len(letters) == 0 {
+		letters = defaultTry
+	}
+	runes := []rune(word)
+	for i := 0; i <= len(runes); i++ {
+		head, tail := string(runes[:i]), runes[i:]
+		for _, ch := range letters {
+			try(head + string(ch) + string(tail)) // insertion
+		}
+		if i == len(runes) {
+			break
+		}
+		try(head + string(tail[1:])) // deletion
+		for _, ch := range letters {
+			if ch != tail[0] {
+				try(head + string(ch) + string(tail[1:])) // replacement
+			}
+		}
+		if i+1 < len(runes) {
+			try(head + string(tail[1]) + string(tail[0]) + string(tail[2:])) // transposition
+		}
+	}
+
+	distances := make(map[string]int, len(candidates))
+	for _, candidate := range candidates {
+		distances[candidate] = distance(word, candidate)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return distances[candidates[i]] < distances[candidates[j]]
+	})
+
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
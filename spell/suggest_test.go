@@ -0,0 +1,56 @@
+package spell
+
+import (
+	"reflect"
+	"testing"
+
+	"bengali-keyboard/phonetic"
+)
+
+func TestSuggest(t *testing.T) {
+	d := parseTestDictionary(t, testAff, testDic)
+
+	// Favors longer candidates, against the default edit distance
+	longestFirst := func(_, b string) int { return -len(b) }
+
+	tests := []struct {
+		word     string
+		limit    int
+		distance func(a, b string) int
+		want     []string
+	}{
+		// ণ is not in TRY, only REP reaches কারণ
+		{"কারন", 0, nil, []string{"কারণ"}},
+
+		// A deletion is one edit and a transposition two
+		{"লকম", 0, nil, []string{"কম", "কলম"}},
+		{"লকম", 1, nil, []string{"কম"}},
+		{"লকম", 0, longestFirst, []string{"কলম", "কম"}},
+
+		// Affixed forms are suggested too
+		{"অবইখ", 0, nil, []string{"অবই"}},
+
+		// The word itself is not a suggestion
+		{"কলম", 0, nil, []string{"কম"}},
+		{"খখখখ", 0, nil, nil},
+	}
+	for _, tt := range tests {
+		if got := d.Suggest(tt.word, tt.limit, tt.distance); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Suggest(%q, %d) = %q, want %q", tt.word, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestTypingDistance(t *testing.T) {
+	// সাপ is one letter from both, but typed sap it is one key from শাপ
+	// (Sap) and two from ষাপ (Shap)
+	d := parseTestDictionary(t, "TRY ষশ\n", "ষাপ\nশাপ\n")
+	engine := phonetic.New()
+
+	if got, want := d.Suggest("সাপ", 0, nil), []string{"ষাপ", "শাপ"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest by edit distance = %q, want %q", got, want)
+	}
+	if got, want := d.Suggest("সাপ", 0, engine.TypingDistance), []string{"শাপ", "ষাপ"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest by typing distance = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,48 @@
+//go:build !js
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"bengali-keyboard/predict"
+)
+
+// runTrain builds a word prediction model from UTF-8 Bengali text files,
+// or from standard input when none are given.
+func runTrain(args []string) error {
+	flags := flag.NewFlagSet("train", flag.ContinueOnError)
+	output := flags.String("o", "", "model file to write (required)")
+	order := flags.Int("order", predict.DefaultOrder, "n-gram order: predict from order-1 previous words")
+	minCount := flags.Int("min-count", 2, "drop word sequences seen fewer times")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *output == "" {
+		return errors.New("usage: train -o MODEL [-order N] [-min-count N] [FILE...]")
+	}
+
+	model := predict.NewModel(*order)
+	if flags.NArg() == 0 {
+		if err := model.Train(os.Stdin); err != nil {
+			return err
+		}
+	}
+	for _, path := range flags.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		err = model.Train(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	model.Prune(*minCount)
+	return model.Save(*output)
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Checked wrappers around the Win32 calls whose failure matters. Each one
+// turns the thread's last error into a Go error naming the function.
+
+const (
+	sendInputAttempts = 3
+	sendInputBackoff  = 5 * time.Millisecond
+)
+
+const (
+	WM_INPUT = 0x00FF
+
+	RIDEV_INPUTSINK = 0x00000100
+
+	HID_USAGE_PAGE_GENERIC     = 0x01
+	HID_USAGE_GENERIC_KEYBOARD = 0x06
+)
+
+var (
+	postMessageW            = user32.NewProc("PostMessageW")
+	registerRawInputDevices = user32.NewProc("RegisterRawInputDevices")
+	getMessageTime          = user32.NewProc("GetMessageTime")
+)
+
+type RAWINPUTDEVICE struct {
+	UsUsagePage uint16
+	UsUsage     uint16
+	DwFlags     uint32
+	HwndTarget  syscall.Handle
+}
+
+// lastError wraps the error LazyProc.Call returns for a failed call. Some
+// functions fail without setting a last error, which leaves it zero.
+func lastError(function string, err error) error {
+	var errno syscall.Errno
+	if errors.As(err, &errno) && errno != 0 {
+		return fmt.Errorf("%s: %w", function, errno)
+	}
+	return fmt.Errorf("%s failed", function)
+}
+
+func getModuleHandle() (syscall.Handle, error) {
+	ret, _, err := getModuleHandleW.Call(0)
+	if ret == 0 {
+		return 0, lastError("GetModuleHandleW", err)
+	}
+	return syscall.Handle(ret), nil
+}
+
+func registerClass(wc *WNDCLASSW) error {
+	if ret, _, err := registerClassW.Call(uintptr(unsafe.Pointer(wc))); ret == 0 {
+		return lastError("RegisterClassW", err)
+	}
+	return nil
+}
+
+// createWindow creates the hidden window that receives tray messages.
+func createWindow(className, windowName *uint16, hInstance syscall.Handle) (syscall.Handle, error) {
+	hwnd, _, err := createWindowExW.Call(
+		0,
+		uintptr(unsafe.Pointer(className)),
+		uintptr(unsafe.Pointer(windowName)),
+		0,
+		0, 0, 0, 0,
+		0, 0,
+		uintptr(hInstance),
+		0,
+	)
+	if hwnd == 0 {
+		return 0, lastError("CreateWindowExW", err)
+	}
+	return syscall.Handle(hwnd), nil
+}
+
+// setHook installs a global hook such as WH_KEYBOARD_LL.
+func setHook(kind uintptr, callback uintptr, hInstance syscall.Handle) (uintptr, error) {
+	hook, _, err := setWindowsHookExW.Call(kind, callback, uintptr(hInstance), 0)
+	if hook == 0 {
+		return 0, lastError("SetWindowsHookExW", err)
+	}
+	return hook, nil
+}
+
+func unhook(hook uintptr) error {
+	if ret, _, err := unhookWindowsHookEx.Call(hook); ret == 0 {
+		return lastError("UnhookWindowsHookEx", err)
+	}
+	return nil
+}
+
+// getMessage waits for the next message. It returns false on WM_QUIT.
+func getMessage(msg *MSG) (bool, error) {
+	ret, _, err := getMessageW.Call(uintptr(unsafe.Pointer(msg)), 0, 0, 0)
+	if ret == ^uintptr(0) {
+		return false, lastError("GetMessageW", err)
+	}
+	return ret != 0, nil
+}
+
+func postMessage(hwnd syscall.Handle, msg uint32, wparam, lparam uintptr) error {
+	if ret, _, err := postMessageW.Call(uintptr(hwnd), uintptr(msg), wparam, lparam); ret == 0 {
+		return lastError("PostMessageW", err)
+	}
+	return nil
+}
+
+func shellNotifyIcon(message uintptr, nid *NOTIFYICONDATAW) error {
+	if ret, _, err := shellNotifyIconW.Call(message, uintptr(unsafe.Pointer(nid))); ret == 0 {
+		return lastError("Shell_NotifyIconW", err)
+	}
+	return nil
+}
+
+func loadIcon() (syscall.Handle, error) {
+	ret, _, err := loadIconW.Call(0, IDI_APPLICATION)
+	if ret == 0 {
+		return 0, lastError("LoadIconW", err)
+	}
+	return syscall.Handle(ret), nil
+}
+
+func loadCursor() (syscall.Handle, error) {
+	ret, _, err := loadCursorW.Call(0, IDC_ARROW)
+	if ret == 0 {
+		return 0, lastError("LoadCursorW", err)
+	}
+	return syscall.Handle(ret), nil
+}
+
+func createMenu() (uintptr, error) {
+	hmenu, _, err := createPopupMenu.Call()
+	if hmenu == 0 {
+		return 0, lastError("CreatePopupMenu", err)
+	}
+	return hmenu, nil
+}
+
+func appendMenu(hmenu, flags, id uintptr, text []uint16) error {
+	var textPtr uintptr
+	if text != nil {
+		textPtr = uintptr(unsafe.Pointer(&text[0]))
+	}
+	if ret, _, err := appendMenuW.Call(hmenu, flags, id, textPtr); ret == 0 {
+		return lastError("AppendMenuW", err)
+	}
+	return nil
+}
+
+func trackMenu(hmenu uintptr, pt POINT, hwnd syscall.Handle) error {
+	ret, _, err := trackPopupMenu.Call(hmenu, TPM_RIGHTBUTTON, uintptr(pt.X), uintptr(pt.Y), 0, uintptr(hwnd), 0)
+	if ret == 0 {
+		return lastError("TrackPopupMenu", err)
+	}
+	return nil
+}
+
+func destroyPopupMenu(hmenu uintptr) error {
+	if ret, _, err := destroyMenu.Call(hmenu); ret == 0 {
+		return lastError("DestroyMenu", err)
+	}
+	return nil
+}
+
+func cursorPos() (POINT, error) {
+	var pt POINT
+	if ret, _, err := getCursorPos.Call(uintptr(unsafe.Pointer(&pt))); ret == 0 {
+		return pt, lastError("GetCursorPos", err)
+	}
+	return pt, nil
+}
+
+// watchKeyboardInput has WM_INPUT sent to hwnd for every key press in
+// the session, also while another window has the focus.
+func watchKeyboardInput(hwnd syscall.Handle) error {
+	device := RAWINPUTDEVICE{
+		UsUsagePage: HID_USAGE_PAGE_GENERIC,
+		UsUsage:     HID_USAGE_GENERIC_KEYBOARD,
+		DwFlags:     RIDEV_INPUTSINK,
+		HwndTarget:  hwnd,
+	}
+	if ret, _, err := registerRawInputDevices.Call(uintptr(unsafe.Pointer(&device)), 1, unsafe.Sizeof(device)); ret == 0 {
+		return lastError("RegisterRawInputDevices", err)
+	}
+	return nil
+}
+
+// messageTime returns the tick count of the message being handled.
+func messageTime() uint32 {
+	ret, _, _ := getMessageTime.Call()
+	return uint32(ret)
+}
+
+// sendInputs injects inputs in order. SendInput returns how many events it
+// inserted; the rest are sent again, up to sendInputAttempts times, since
+// another thread's input can interrupt the stream. Zero inserted events
+// with no error means UIPI blocked the input, which retrying cannot fix.
+func sendInputs(inputs []INPUT) error {
+	for attempt := 1; len(inputs) > 0; attempt++ {
+		n, _, err := sendInput.Call(
+			uintptr(len(inputs)),
+			uintptr(unsafe.Pointer(&inputs[0])),
+			unsafe.Sizeof(inputs[0]),
+		)
+		inputs = inputs[n:]
+		if len(inputs) == 0 {
+			break
+		}
+
+		if n == 0 {
+			var errno syscall.Errno
+			if !errors.As(err, &errno) || errno == 0 {
+				return errors.New("SendInput: blocked by a higher integrity window")
+			}
+		}
+		if attempt == sendInputAttempts {
+			return fmt.Errorf("%w (%d events not sent)", lastError("SendInput", err), len(inputs))
+		}
+		time.Sleep(sendInputBackoff)
+	}
+	return nil
+}